@@ -0,0 +1,148 @@
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+)
+
+// BaselineVersion is the version of the baseline file format.
+const BaselineVersion = 1
+
+// Baseline records known findings, which do not fail the check. Findings are
+// identified by file, object, container and rule but not by their position,
+// so moving lines in a manifest does not invalidate the baseline.
+type Baseline struct {
+	Version  int             `json:"version"`
+	Findings []BaselineEntry `json:"findings"`
+}
+
+// BaselineEntry counts the known findings with the same identity.
+type BaselineEntry struct {
+	File      string `json:"file"`
+	Kind      string `json:"kind,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Container string `json:"container,omitempty"`
+	Rule      string `json:"rule"`
+	Count     int    `json:"count"`
+}
+
+type baselineKey struct {
+	file      string
+	kind      string
+	namespace string
+	name      string
+	container string
+	rule      string
+}
+
+func baselineKeyOf(finding Finding) baselineKey {
+	return baselineKey{
+		file:      filepath.ToSlash(filepath.Clean(finding.File)),
+		kind:      finding.Kind,
+		namespace: finding.Namespace,
+		name:      finding.Name,
+		container: finding.Container,
+		rule:      finding.Rule,
+	}
+}
+
+// NewBaseline returns the baseline of the given findings sorted by identity.
+func NewBaseline(findings []Finding) *Baseline {
+	counts := make(map[baselineKey]int)
+	for _, finding := range findings {
+		counts[baselineKeyOf(finding)]++
+	}
+	baseline := &Baseline{Version: BaselineVersion, Findings: []BaselineEntry{}}
+	for key, count := range counts {
+		baseline.Findings = append(baseline.Findings, BaselineEntry{
+			File:      key.file,
+			Kind:      key.kind,
+			Namespace: key.namespace,
+			Name:      key.name,
+			Container: key.container,
+			Rule:      key.rule,
+			Count:     count,
+		})
+	}
+	sort.Slice(baseline.Findings, func(i, j int) bool {
+		a, b := baseline.Findings[i], baseline.Findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Container != b.Container {
+			return a.Container < b.Container
+		}
+		return a.Rule < b.Rule
+	})
+	return baseline
+}
+
+// LoadBaseline reads the baseline file.
+func LoadBaseline(file string) (*Baseline, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read baseline %s failed: %v", file, err)
+	}
+	baseline := &Baseline{}
+	if err := json.Unmarshal(content, baseline); err != nil {
+		return nil, fmt.Errorf("parse baseline %s failed: %v", file, err)
+	}
+	if baseline.Version != BaselineVersion {
+		return nil, fmt.Errorf("baseline version %d not supported in %s", baseline.Version, file)
+	}
+	return baseline, nil
+}
+
+// Save writes the baseline file.
+func (b *Baseline) Save(file string) error {
+	content, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal baseline failed: %v", err)
+	}
+	if err := ioutil.WriteFile(file, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("write baseline %s failed: %v", file, err)
+	}
+	return nil
+}
+
+// Apply moves the findings of the report known by the baseline to the
+// baselined findings of the report. If an identity has more findings than
+// recorded, the additional ones are kept as new findings.
+func (b *Baseline) Apply(report *Report) {
+	remaining := make(map[baselineKey]int)
+	for _, entry := range b.Findings {
+		key := baselineKey{
+			file:      filepath.ToSlash(filepath.Clean(entry.File)),
+			kind:      entry.Kind,
+			namespace: entry.Namespace,
+			name:      entry.Name,
+			container: entry.Container,
+			rule:      entry.Rule,
+		}
+		remaining[key] += entry.Count
+	}
+	var findings []Finding
+	for _, finding := range report.Findings {
+		key := baselineKeyOf(finding)
+		if remaining[key] > 0 {
+			remaining[key]--
+			report.Baselined = append(report.Baselined, finding)
+			continue
+		}
+		findings = append(findings, finding)
+	}
+	report.Findings = findings
+}
@@ -0,0 +1,82 @@
+package check_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/seibert-media/k8s-manifest-check/check"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Baseline", func() {
+	const podWithoutLimits = `apiVersion: v1
+kind: Pod
+metadata:
+  name: hello-world
+spec:
+  containers:
+  - name: hello
+    image: "ubuntu:14.04"
+    resources:
+      requests:
+        cpu: 100m
+        memory: 100Mi
+`
+	var baseline *check.Baseline
+	BeforeEach(func() {
+		report := &check.Report{}
+		check.Content(report, "pod.yaml", []byte(podWithoutLimits))
+		Expect(report.Findings).To(HaveLen(2))
+		baseline = check.NewBaseline(report.Findings)
+	})
+	It("count findings by file, object, container and rule", func() {
+		Expect(baseline.Findings).To(Equal([]check.BaselineEntry{
+			{File: "pod.yaml", Kind: "Pod", Name: "hello-world", Container: "hello", Rule: "cpu-limit-nonzero", Count: 1},
+			{File: "pod.yaml", Kind: "Pod", Name: "hello-world", Container: "hello", Rule: "memory-limit-nonzero", Count: 1},
+		}))
+	})
+	It("ignore known findings after lines moved", func() {
+		report := &check.Report{}
+		check.Content(report, "pod.yaml", []byte("# moved by a comment\n\n"+podWithoutLimits))
+		baseline.Apply(report)
+		Expect(report.Findings).To(BeEmpty())
+		Expect(report.Baselined).To(HaveLen(2))
+		Expect(report.Valid()).To(BeTrue())
+	})
+	It("keep findings not in the baseline", func() {
+		report := &check.Report{}
+		check.Content(report, "pod.yaml", []byte(podWithoutLimits+`  - name: sidecar
+    image: "ubuntu:14.04"
+    resources:
+      requests:
+        cpu: 100m
+        memory: 100Mi
+`))
+		baseline.Apply(report)
+		Expect(report.Findings).To(HaveLen(2))
+		Expect(report.Findings[0].Container).To(Equal("sidecar"))
+		Expect(report.Findings[1].Container).To(Equal("sidecar"))
+		Expect(report.Baselined).To(HaveLen(2))
+	})
+	It("save and load baseline", func() {
+		dir, err := ioutil.TempDir("", "baseline")
+		Expect(err).To(BeNil())
+		defer os.RemoveAll(dir)
+		file := filepath.Join(dir, "baseline.json")
+		Expect(baseline.Save(file)).To(BeNil())
+		loaded, err := check.LoadBaseline(file)
+		Expect(err).To(BeNil())
+		Expect(loaded).To(Equal(baseline))
+	})
+	It("return error for unsupported version", func() {
+		dir, err := ioutil.TempDir("", "baseline")
+		Expect(err).To(BeNil())
+		defer os.RemoveAll(dir)
+		file := filepath.Join(dir, "baseline.json")
+		Expect(ioutil.WriteFile(file, []byte(`{"version": 2, "findings": []}`), 0644)).To(BeNil())
+		_, err = check.LoadBaseline(file)
+		Expect(err).NotTo(BeNil())
+	})
+})
@@ -1,70 +1,256 @@
 package check
 
 import (
-	"errors"
+	"bufio"
+	"bytes"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"runtime"
+	"sort"
+	"sync"
 
 	"github.com/ghodss/yaml"
 	"github.com/golang/glog"
-	appsv1 "k8s.io/api/apps/v1"
-	appsv1beta1 "k8s.io/api/apps/v1beta1"
-	appsv1beta2 "k8s.io/api/apps/v1beta2"
-	corev1 "k8s.io/api/core/v1"
-	extv1beta1 "k8s.io/api/extensions/v1beta1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	k8s_runtime "k8s.io/apimachinery/pkg/runtime"
+	k8s_yaml "k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/kubernetes/scheme"
 )
 
-func Path(path string) error {
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return fmt.Errorf("manifest %s not found", path)
-		os.Exit(1)
+// Checker checks manifests with the enabled rules of its registry.
+type Checker struct {
+	Registry *Registry
+	// Config configures the rules per object, it may be nil.
+	Config *Config
+	// Include and Exclude are glob patterns for files found in directories.
+	// Without include patterns all manifest files are checked.
+	Include []string
+	Exclude []string
+	// Jobs is the number of files checked concurrently by Paths.
+	Jobs int
+	// Schemas validate objects of all kinds with schema, it may be nil.
+	Schemas *Schemas
+}
+
+// New returns a checker using the default registry and one job per CPU.
+func New() *Checker {
+	return &Checker{Registry: DefaultRegistry, Jobs: runtime.NumCPU()}
+}
+
+// Path checks the manifest or directory at path with a new checker.
+// References between objects are not checked, see Checker.References.
+func Path(report *Report, path string) {
+	New().Path(report, path)
+}
+
+// Content checks the content with a new checker. References between objects
+// are not checked, see Checker.References.
+func Content(report *Report, file string, content []byte) {
+	New().Content(report, file, content)
+}
+
+// Reader checks the manifests read from reader with a new checker.
+// References between objects are not checked, see Checker.References.
+func Reader(report *Report, reader io.Reader, source string) {
+	New().Reader(report, reader, source)
+}
+
+// Path reads the manifest at path and adds all findings to the report. If
+// path is a directory all manifest files found in it are checked.
+func (c *Checker) Path(report *Report, path string) {
+	c.Paths(report, path)
+}
+
+// Paths checks the manifests and directories at the given paths like Path.
+// Up to Jobs files are checked concurrently, the results are added to the
+// report ordered by file path.
+func (c *Checker) Paths(report *Report, paths ...string) {
+	var tasks []task
+	for _, path := range paths {
+		tasks = append(tasks, c.tasks(path)...)
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].path < tasks[j].path
+	})
+	jobs := c.Jobs
+	if jobs < 1 {
+		jobs = 1
+	}
+	reports := make([]*Report, len(tasks))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				reports[index] = &Report{}
+				tasks[index].run(reports[index])
+			}
+		}()
+	}
+	for index := range tasks {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+	for _, r := range reports {
+		report.Merge(r)
 	}
+}
+
+// task checks a single file or reports why a path can not be checked.
+type task struct {
+	path string
+	run  func(report *Report)
+}
+
+// tasks returns the task for the manifest at path or for every manifest file
+// found in the directory at path.
+func (c *Checker) tasks(path string) []task {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return []task{{path: path, run: func(report *Report) {
+			report.Files = append(report.Files, path)
+			report.Add(Finding{File: path, Rule: ReadRule, Severity: SeverityError, Message: "manifest not found"})
+		}}}
+	}
+	if err == nil && info.IsDir() {
+		files, err := c.Files(path)
+		if err != nil {
+			glog.V(4).Infof("walk directory %s failed: %v", path, err)
+			return []task{{path: path, run: func(report *Report) {
+				report.Files = append(report.Files, path)
+				report.Add(Finding{File: path, Rule: ReadRule, Severity: SeverityError, Message: "read directory failed"})
+			}}}
+		}
+		glog.V(4).Infof("found %d manifests in %s", len(files), path)
+		var result []task
+		for _, file := range files {
+			file := file
+			result = append(result, task{path: file, run: func(report *Report) {
+				c.file(report, file)
+			}})
+		}
+		return result
+	}
+	return []task{{path: path, run: func(report *Report) {
+		c.file(report, path)
+	}}}
+}
+
+func (c *Checker) file(report *Report, path string) {
 	content, err := ioutil.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("read manifest %s failed", path)
+		glog.V(4).Infof("read manifest %s failed: %v", path, err)
+		report.Files = append(report.Files, path)
+		report.Add(Finding{File: path, Rule: ReadRule, Severity: SeverityError, Message: "read manifest failed"})
+		return
+	}
+	c.Content(report, path, content)
+}
+
+// Content splits the given content into YAML documents, checks each of them
+// and adds all findings to the report. The file is used to attribute the findings.
+func (c *Checker) Content(report *Report, file string, content []byte) {
+	c.Reader(report, bytes.NewReader(content), file)
+}
+
+// Reader reads YAML documents from r until EOF and checks each of them as
+// soon as it is read. The source is used as file of all findings.
+func (c *Checker) Reader(report *Report, r io.Reader, source string) {
+	report.Files = append(report.Files, source)
+	reader := k8s_yaml.NewYAMLReader(bufio.NewReader(r))
+	documents := 0
+	line := 1
+	for index := 1; ; index++ {
+		document, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			glog.V(4).Infof("read document %d of %s failed: %v", index, source, err)
+			report.Add(Finding{File: source, Document: index, Rule: ReadRule, Severity: SeverityError, Message: "read document failed"})
+			return
+		}
+		firstLine := line
+		// the reader drops the separator line following the document
+		line += bytes.Count(document, []byte("\n")) + 1
+		if isEmptyDocument(document) {
+			glog.V(4).Infof("document %d is empty", index)
+			continue
+		}
+		documents++
+		c.document(report, source, index, firstLine, document)
 	}
-	if err := Content(content); err != nil {
-		return fmt.Errorf("%s in %s", err.Error(), path)
+	if documents == 0 {
+		report.Add(Finding{File: source, Rule: ParseRule, Severity: SeverityError, Message: "content is empty"})
 	}
-	return nil
 }
 
-func Content(content []byte) error {
-	if len(content) == 0 {
-		return errors.New("content is empty")
+// isEmptyDocument returns true if the document only contains whitespace, comments and separators.
+func isEmptyDocument(document []byte) bool {
+	for _, line := range bytes.Split(document, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 && line[0] != '#' && !bytes.Equal(line, []byte("---")) {
+			return false
+		}
 	}
-	obj, err := parseObject(content)
+	return true
+}
+
+// document checks the document with the given index, which starts at firstLine in the file.
+func (c *Checker) document(report *Report, file string, index int, firstLine int, content []byte) {
+	obj, err := parseObject(content, c.Schemas)
 	if err != nil {
 		glog.V(4).Infof("parse content failed: %v", err)
-		return errors.New("parse content failed")
-	}
-	switch o := obj.(type) {
-	case *corev1.Pod:
-		return checkContainers(o.Spec.Containers)
-	case *appsv1.Deployment:
-		return checkContainers(o.Spec.Template.Spec.Containers)
-	case *extv1beta1.Deployment:
-		return checkContainers(o.Spec.Template.Spec.Containers)
-	case *appsv1beta1.Deployment:
-		return checkContainers(o.Spec.Template.Spec.Containers)
-	case *appsv1beta2.Deployment:
-		return checkContainers(o.Spec.Template.Spec.Containers)
-	default:
-		glog.V(4).Infof("type %T not checked", obj)
-	}
-	return nil
-}
-
-func parseObject(content []byte) (k8s_runtime.Object, error) {
+		finding := Finding{File: file, Document: index, Line: firstLine, Rule: ParseRule, Severity: SeverityError, Message: "parse content failed"}
+		if line, ok := yamlErrorLine(err); ok {
+			finding.Line = firstLine + line - 1
+		}
+		report.Add(finding)
+		return
+	}
+	object := NewObject(file, index, obj)
+	object.positions = parsePositions(content, firstLine)
+	object.config = c.Config
+	object.content = content
+	object.schemas = c.Schemas
+	report.Objects = append(report.Objects, object)
+	c.add(report, object, c.Registry.Check(object))
+}
+
+// References checks the references between all objects of the report with
+// the graph rules and adds the findings to the report, which stays ordered by
+// file and document. It is called once all manifests of a run are checked.
+func (c *Checker) References(report *Report) {
+	graph := NewGraph(report.Objects)
+	for _, object := range report.Objects {
+		c.add(report, object, c.Registry.CheckGraph(object, graph))
+	}
+	report.sort()
+}
+
+// add adds the findings of the object to the report unless they are suppressed by its annotations.
+func (c *Checker) add(report *Report, object *Object, findings []Finding) {
+	for _, finding := range findings {
+		if justification, ok := object.Suppression(finding); ok {
+			finding.Justification = justification
+			report.Suppress(finding)
+			continue
+		}
+		report.Add(finding)
+	}
+}
+
+func parseObject(content []byte, schemas *Schemas) (k8s_runtime.Object, error) {
 	content, err := yaml.YAMLToJSON(content)
 	if err != nil {
 		return nil, fmt.Errorf("yaml to json failed: %v", err)
 	}
-	obj, err := kind(content)
+	obj, err := kind(content, schemas)
 	if err != nil {
 		return nil, fmt.Errorf("create object by content failed: %v", err)
 	}
@@ -74,7 +260,16 @@ func parseObject(content []byte) (k8s_runtime.Object, error) {
 	return obj, nil
 }
 
-func kind(content []byte) (k8s_runtime.Object, error) {
+// kind returns an empty object of the kind of the content or nil to decode it
+// unstructured. Decoded unstructured are
+//   - Secrets, whose values may be encrypted and therefore invalid base64,
+//   - CustomResourceDefinitions,
+//   - kinds missing in the vendored API versions if schemas has their schema,
+//     like custom resources,
+//   - SealedSecrets and
+//   - deprecated or replacement API versions missing in the vendored API
+//     versions, like networking.k8s.io/v1 Ingresses.
+func kind(content []byte, schemas *Schemas) (k8s_runtime.Object, error) {
 	_, kind, err := unstructured.UnstructuredJSONScheme.Decode(content, nil, nil)
 	if err != nil {
 		return nil, fmt.Errorf("unmarshal to unknown failed: %v", err)
@@ -82,40 +277,28 @@ func kind(content []byte) (k8s_runtime.Object, error) {
 	if kind.Kind == "Secret" {
 		return nil, nil
 	}
-	obj, err := scheme.Scheme.New(*kind)
-	if err != nil {
-		return nil, fmt.Errorf("create object failed: %v", err)
-	}
-	return obj, nil
-}
-
-func checkContainers(containers []corev1.Container) error {
-	for _, container := range containers {
-		if err := Resources(container.Resources); err != nil {
-			return err
-		}
+	// CustomResourceDefinitions are missing in the vendored scheme, but are
+	// checked together with their custom resources
+	if kind.Group == "apiextensions.k8s.io" && kind.Kind == "CustomResourceDefinition" {
+		return &unstructured.Unstructured{}, nil
 	}
-	return nil
-}
-
-func Resources(resourceRequirements corev1.ResourceRequirements) error {
-	if resourceRequirements.Requests.Cpu().IsZero() {
-		return errors.New("cpu request is zero")
-	}
-	if resourceRequirements.Requests.Memory().IsZero() {
-		return errors.New("memory request is zero")
-	}
-	if resourceRequirements.Limits.Memory().IsZero() {
-		return errors.New("memory limit is zero")
+	obj, err := scheme.Scheme.New(*kind)
+	// kinds with schema, like custom resources and kinds of newer Kubernetes
+	// releases, are validated by the schema
+	if k8s_runtime.IsNotRegisteredError(err) && schemas.Has(kind.GroupVersion().String(), kind.Kind) {
+		return &unstructured.Unstructured{}, nil
 	}
-	if resourceRequirements.Limits.Cpu().IsZero() {
-		return errors.New("cpu limit is zero")
+	// SealedSecrets are checked like Secrets without their CRD
+	if k8s_runtime.IsNotRegisteredError(err) && kind.Group == "bitnami.com" && kind.Kind == "SealedSecret" {
+		return &unstructured.Unstructured{}, nil
 	}
-	if resourceRequirements.Requests.Cpu().Cmp(*resourceRequirements.Limits.Cpu()) > 0 {
-		return errors.New("cpu request must be less than or equal to cpu limit")
+	// API versions of the deprecation table are reported by apiVersion even if
+	// they are missing in the vendored API versions
+	if k8s_runtime.IsNotRegisteredError(err) && knownAPI(kind.GroupVersion().String(), kind.Kind) {
+		return &unstructured.Unstructured{}, nil
 	}
-	if resourceRequirements.Requests.Memory().Cmp(*resourceRequirements.Limits.Memory()) > 0 {
-		return errors.New("memory request must be less than or equal to memory limit")
+	if err != nil {
+		return nil, fmt.Errorf("create object failed: %v", err)
 	}
-	return nil
+	return obj, nil
 }
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"strings"
 	"testing"
 
 	"github.com/seibert-media/k8s-manifest-check/check"
@@ -27,21 +28,28 @@ var _ = Describe("Check", func() {
 			Expect(err).To(BeNil())
 			manifestpath = path.Join(dir, "not-existing-file")
 		})
-		It("return file not found error", func() {
-			err := check.Path(manifestpath)
-			Expect(err).NotTo(BeNil())
+		It("report file not found", func() {
+			report := &check.Report{}
+			check.Path(report, manifestpath)
+			Expect(report.Findings).To(HaveLen(1))
+			Expect(report.Findings[0].String()).To(Equal(fmt.Sprintf("manifest not found in %s [read]", manifestpath)))
 		})
 	})
-	Context("not readable path", func() {
+	Context("empty directory", func() {
 		var manifestpath string
 		BeforeEach(func() {
 			dir, err := ioutil.TempDir("", "file")
 			Expect(err).To(BeNil())
 			manifestpath = dir
 		})
-		It("return file not found error", func() {
-			err := check.Path(manifestpath)
-			Expect(err).NotTo(BeNil())
+		AfterEach(func() {
+			os.RemoveAll(manifestpath)
+		})
+		It("report nothing", func() {
+			report := &check.Report{}
+			check.Path(report, manifestpath)
+			Expect(report.Files).To(BeEmpty())
+			Expect(report.Valid()).To(BeTrue())
 		})
 	})
 	Context("empty content", func() {
@@ -52,10 +60,11 @@ var _ = Describe("Check", func() {
 		AfterEach(func() {
 			os.Remove(manifestpath)
 		})
-		It("return file not found error", func() {
-			err := check.Path(manifestpath)
-			Expect(err).NotTo(BeNil())
-			Expect(err.Error()).To(Equal(fmt.Sprintf("content is empty in %s", manifestpath)))
+		It("report content is empty", func() {
+			report := &check.Report{}
+			check.Path(report, manifestpath)
+			Expect(report.Findings).To(HaveLen(1))
+			Expect(report.Findings[0].String()).To(Equal(fmt.Sprintf("content is empty in %s [parse]", manifestpath)))
 		})
 	})
 	Context("invalid content", func() {
@@ -66,10 +75,11 @@ var _ = Describe("Check", func() {
 		AfterEach(func() {
 			os.Remove(manifestpath)
 		})
-		It("return file not found error", func() {
-			err := check.Path(manifestpath)
-			Expect(err).NotTo(BeNil())
-			Expect(err.Error()).To(Equal(fmt.Sprintf("parse content failed in %s", manifestpath)))
+		It("report parse failure", func() {
+			report := &check.Report{}
+			check.Path(report, manifestpath)
+			Expect(report.Findings).To(HaveLen(1))
+			Expect(report.Findings[0].String()).To(Equal(fmt.Sprintf("parse content failed in %s:1 (document 1) [parse]", manifestpath)))
 		})
 	})
 	Context("valid content", func() {
@@ -95,15 +105,172 @@ spec:
 		AfterEach(func() {
 			os.Remove(manifestpath)
 		})
-		It("return file not found error", func() {
-			err := check.Path(manifestpath)
-			Expect(err).To(BeNil())
+		It("report nothing", func() {
+			report := &check.Report{}
+			check.Path(report, manifestpath)
+			Expect(report.Valid()).To(BeTrue())
+		})
+	})
+	Context("multiple documents", func() {
+		var manifestpath string
+		AfterEach(func() {
+			os.Remove(manifestpath)
+		})
+		It("report nothing if all documents are valid", func() {
+			manifestpath = writeTempFile(`---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: hello-world
+data:
+  hello: world
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: hello-world
+spec:
+  ports:
+  - port: 80
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: hello-world
+spec:
+  template:
+    spec:
+      containers:
+      - name: hello
+        image: "ubuntu:14.04"
+        resources:
+          limits:
+            cpu: 100m
+            memory: 50Mi
+          requests:
+            cpu: 10m
+            memory: 10Mi
+`)
+			report := &check.Report{}
+			check.Path(report, manifestpath)
+			Expect(report.Valid()).To(BeTrue())
+		})
+		It("report findings with document index of invalid document", func() {
+			manifestpath = writeTempFile(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: hello-world
+---
+# comment only
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: hello-world
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: hello-world
+spec:
+  template:
+    spec:
+      containers:
+      - name: hello
+        image: "ubuntu:14.04"
+`)
+			report := &check.Report{}
+			check.Path(report, manifestpath)
+			Expect(report.Objects).To(HaveLen(3))
+			Expect(report.Objects[2].Document).To(Equal(4))
+			Expect(report.Findings).To(HaveLen(4))
+			Expect(report.Findings[0].String()).To(Equal(fmt.Sprintf("cpu request is zero in %s:21:7 (document 4, Deployment hello-world, container hello) [cpu-request-nonzero]", manifestpath)))
+			Expect(report.Findings[3].Rule).To(Equal("cpu-limit-nonzero"))
+		})
+		It("report content is empty if all documents are empty", func() {
+			manifestpath = writeTempFile("---\n# comment\n---\n")
+			report := &check.Report{}
+			check.Path(report, manifestpath)
+			Expect(report.Findings).To(HaveLen(1))
+			Expect(report.Findings[0].String()).To(Equal(fmt.Sprintf("content is empty in %s [parse]", manifestpath)))
 		})
 	})
 })
 
+var _ = Describe("Reader", func() {
+	It("check all documents of the stream", func() {
+		report := &check.Report{}
+		check.Reader(report, strings.NewReader(validPod+"---\n"+validPod+"---\nhello world\n"), "helm template")
+		Expect(report.Files).To(Equal([]string{"helm template"}))
+		Expect(report.Objects).To(HaveLen(2))
+		Expect(report.Findings).To(HaveLen(1))
+		Expect(report.Findings[0].String()).To(Equal("parse content failed in helm template:33 (document 3) [parse]"))
+	})
+	It("report empty stream", func() {
+		report := &check.Report{}
+		check.Reader(report, strings.NewReader(""), "stdin")
+		Expect(report.Findings).To(HaveLen(1))
+		Expect(report.Findings[0].Message).To(Equal("content is empty"))
+	})
+})
+
+var _ = Describe("Containers", func() {
+	It("return init containers and containers", func() {
+		spec := corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "init"}},
+			Containers:     []corev1.Container{{Name: "first"}, {Name: "second"}},
+		}
+		containers := check.Containers(spec)
+		Expect(containers).To(HaveLen(3))
+		Expect(containers[0].Name).To(Equal("init"))
+		Expect(containers[0].Type).To(Equal(check.InitContainerType))
+		Expect(containers[2].Name).To(Equal("second"))
+		Expect(containers[2].Type).To(Equal(check.RegularContainerType))
+		Expect(containers[2].Index).To(Equal(1))
+	})
+	It("check resources of init containers", func() {
+		report := &check.Report{}
+		check.Content(report, "pod.yaml", []byte(`apiVersion: v1
+kind: Pod
+metadata:
+  name: hello-world
+  namespace: default
+spec:
+  initContainers:
+  - name: setup
+    image: "ubuntu:14.04"
+  containers:
+  - name: hello
+    image: "ubuntu:14.04"
+    resources:
+      limits:
+        cpu: 100m
+        memory: 50Mi
+      requests:
+        cpu: 10m
+        memory: 10Mi
+`))
+		Expect(report.Findings).To(HaveLen(4))
+		Expect(report.Findings[0].String()).To(Equal("cpu request is zero in pod.yaml:8:3 (document 1, Pod default/hello-world, initContainer setup) [cpu-request-nonzero]"))
+	})
+})
+
+var _ = Describe("Report", func() {
+	It("format finding without details", func() {
+		finding := check.Finding{File: "pod.yaml", Message: "manifest not found"}
+		Expect(finding.String()).To(Equal("manifest not found in pod.yaml"))
+	})
+	It("collect findings", func() {
+		report := &check.Report{}
+		Expect(report.Valid()).To(BeTrue())
+		report.Add(check.Finding{Rule: "a"}, check.Finding{Rule: "b"})
+		Expect(report.Findings).To(HaveLen(2))
+		Expect(report.Valid()).To(BeFalse())
+	})
+})
+
 var _ = Describe("Resources", func() {
-	var err error
+	var findings []check.Finding
 	var requirements corev1.ResourceRequirements
 	BeforeEach(func() {
 		requirements = corev1.ResourceRequirements{
@@ -117,49 +284,53 @@ var _ = Describe("Resources", func() {
 			},
 		}
 	})
-	It("return no error with valid resources", func() {
-		err = check.Resources(requirements)
-		Expect(err).To(BeNil())
+	It("return no finding with valid resources", func() {
+		findings = check.Resources(requirements)
+		Expect(findings).To(BeEmpty())
 	})
-	It("return error if request cpu missing", func() {
+	It("return finding if request cpu missing", func() {
 		delete(requirements.Requests, "cpu")
-		err = check.Resources(requirements)
-		Expect(err).NotTo(BeNil())
+		findings = check.Resources(requirements)
+		Expect(findings).To(HaveLen(1))
 	})
-	It("return error if request memory missing", func() {
+	It("return finding if request memory missing", func() {
 		delete(requirements.Requests, "memory")
-		err = check.Resources(requirements)
-		Expect(err).NotTo(BeNil())
+		findings = check.Resources(requirements)
+		Expect(findings).To(HaveLen(1))
 	})
-	It("return error if limit cpu missing", func() {
+	It("return finding if limit cpu missing", func() {
 		delete(requirements.Limits, "cpu")
-		err = check.Resources(requirements)
-		Expect(err).NotTo(BeNil())
+		findings = check.Resources(requirements)
+		Expect(findings).To(HaveLen(1))
 	})
-	It("return error if limit memory missing", func() {
+	It("return finding if limit memory missing", func() {
 		delete(requirements.Limits, "memory")
-		err = check.Resources(requirements)
-		Expect(err).NotTo(BeNil())
+		findings = check.Resources(requirements)
+		Expect(findings).To(HaveLen(1))
 	})
-	It("return error if cpu limit is below cpu request", func() {
+	It("return finding if cpu limit is below cpu request", func() {
 		requirements.Requests["cpu"] = resource.MustParse("20m")
-		err = check.Resources(requirements)
-		Expect(err).NotTo(BeNil())
+		findings = check.Resources(requirements)
+		Expect(findings).To(HaveLen(1))
 	})
-	It("return error if memory limit is below memory request", func() {
+	It("return finding if memory limit is below memory request", func() {
 		requirements.Requests["memory"] = resource.MustParse("20m")
-		err = check.Resources(requirements)
-		Expect(err).NotTo(BeNil())
+		findings = check.Resources(requirements)
+		Expect(findings).To(HaveLen(1))
 	})
-	It("return no error if cpu limit is above cpu request", func() {
+	It("return no finding if cpu limit is above cpu request", func() {
 		requirements.Limits["cpu"] = resource.MustParse("20m")
-		err = check.Resources(requirements)
-		Expect(err).To(BeNil())
+		findings = check.Resources(requirements)
+		Expect(findings).To(BeEmpty())
+	})
+	It("return all findings if resources are missing", func() {
+		findings = check.Resources(corev1.ResourceRequirements{})
+		Expect(findings).To(HaveLen(4))
 	})
-	It("return no error if memory limit is above memory request", func() {
+	It("return no finding if memory limit is above memory request", func() {
 		requirements.Limits["memory"] = resource.MustParse("20m")
-		err = check.Resources(requirements)
-		Expect(err).To(BeNil())
+		findings = check.Resources(requirements)
+		Expect(findings).To(BeEmpty())
 	})
 })
 
@@ -0,0 +1,298 @@
+package check
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ConfigFile is the name of the configuration file searched from the working directory upward.
+const ConfigFile = ".k8s-manifest-check.yaml"
+
+// Config configures rules globally and for objects matched by overrides.
+type Config struct {
+	// KubernetesVersion is the version of the target cluster like "1.22".
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+	// Schemas are OpenAPI documents or directories of them, relative to the config file.
+	Schemas []string `json:"schemas,omitempty"`
+	// CRDs are CustomResourceDefinition manifests or directories of them, relative to the config file.
+	CRDs    []string              `json:"crds,omitempty"`
+	Secrets SecretPolicy          `json:"secrets,omitempty"`
+	Images  ImagePolicy           `json:"images,omitempty"`
+	Rules   map[string]RuleConfig `json:"rules,omitempty"`
+	// Overrides are applied in order on top of the rules for all matching objects.
+	Overrides []Override `json:"overrides,omitempty"`
+
+	targetVersion *KubernetesVersion
+}
+
+// RuleConfig configures a single rule. Unset fields keep the defaults of the rule.
+type RuleConfig struct {
+	Enabled  *bool    `json:"enabled,omitempty"`
+	Severity Severity `json:"severity,omitempty"`
+	// Thresholds are numeric limits of the rule by name.
+	Thresholds map[string]float64 `json:"thresholds,omitempty"`
+}
+
+// SecretPolicy configures which Secret manifests are accepted.
+type SecretPolicy struct {
+	// Encryption are the accepted encryption formats of secret values, all
+	// known formats by default. The format "none" accepts plaintext values.
+	Encryption []string `json:"encryption,omitempty"`
+}
+
+// ImagePolicy configures the registries container images may be pulled from.
+type ImagePolicy struct {
+	// AllowedRegistries are glob patterns of the allowed registries, all
+	// registries are allowed if empty. Patterns with a slash match the
+	// registry and repository like "docker.io/library/*".
+	AllowedRegistries []string `json:"allowedRegistries,omitempty"`
+	// DeniedRegistries are glob patterns of registries, which are never allowed.
+	DeniedRegistries []string `json:"deniedRegistries,omitempty"`
+}
+
+// Override configures rules for objects matching all of its kinds, namespaces and label selector.
+type Override struct {
+	Kinds []string `json:"kinds,omitempty"`
+	// Namespaces are glob patterns.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Selector is a label selector like "app=web,tier!=frontend" matched against the labels of the object.
+	Selector string                `json:"selector,omitempty"`
+	Rules    map[string]RuleConfig `json:"rules"`
+
+	selector labels.Selector
+}
+
+// LoadConfig reads the configuration file.
+func LoadConfig(file string) (*Config, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read config %s failed: %v", file, err)
+	}
+	config, err := ParseConfig(content)
+	if err != nil {
+		return nil, fmt.Errorf("%v in %s", err, file)
+	}
+	for _, paths := range [][]string{config.Schemas, config.CRDs} {
+		for i, path := range paths {
+			if !filepath.IsAbs(path) {
+				paths[i] = filepath.Join(filepath.Dir(file), path)
+			}
+		}
+	}
+	return config, nil
+}
+
+// ParseConfig parses the YAML configuration. Unknown fields are errors.
+func ParseConfig(content []byte) (*Config, error) {
+	content, err := yaml.YAMLToJSON(content)
+	if err != nil {
+		return nil, fmt.Errorf("parse config failed: %v", err)
+	}
+	config := &Config{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return nil, fmt.Errorf("parse config failed: %v", err)
+	}
+	if config.KubernetesVersion != "" {
+		if err := config.SetKubernetesVersion(config.KubernetesVersion); err != nil {
+			return nil, err
+		}
+	}
+	for i := range config.Overrides {
+		selector, err := labels.Parse(config.Overrides[i].Selector)
+		if err != nil {
+			return nil, fmt.Errorf("parse selector of override %d failed: %v", i+1, err)
+		}
+		config.Overrides[i].selector = selector
+	}
+	return config, nil
+}
+
+// FindConfig searches the configuration file in dir and all of its parents.
+func FindConfig(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		file := filepath.Join(dir, ConfigFile)
+		if _, err := os.Stat(file); err == nil {
+			return file, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// SetKubernetesVersion sets the version of the target cluster.
+func (c *Config) SetKubernetesVersion(value string) error {
+	version, err := ParseKubernetesVersion(value)
+	if err != nil {
+		return err
+	}
+	c.KubernetesVersion = value
+	c.targetVersion = &version
+	return nil
+}
+
+// TargetVersion returns the version of the target cluster if configured.
+func (c *Config) TargetVersion() (KubernetesVersion, bool) {
+	if c == nil || c.targetVersion == nil {
+		return KubernetesVersion{}, false
+	}
+	return *c.targetVersion, true
+}
+
+// SecretEncryption returns the accepted encryption formats of secret values.
+func (c *Config) SecretEncryption() []string {
+	if c == nil || len(c.Secrets.Encryption) == 0 {
+		return secretEncryptions
+	}
+	return c.Secrets.Encryption
+}
+
+// ImagePolicy returns the configured registries of container images.
+func (c *Config) ImagePolicy() ImagePolicy {
+	if c == nil {
+		return ImagePolicy{}
+	}
+	return c.Images
+}
+
+// Validate returns an error if the configuration references unknown rules,
+// thresholds, severities or secret encryption formats or has invalid
+// registry patterns.
+func (c *Config) Validate(registry *Registry) error {
+	validate := func(rules map[string]RuleConfig) error {
+		for id, config := range rules {
+			rule, ok := registry.Rule(id)
+			if !ok {
+				return fmt.Errorf("rule %s not found", id)
+			}
+			if config.Severity != "" && !config.Severity.Valid() {
+				return fmt.Errorf("severity %s of rule %s is invalid", config.Severity, id)
+			}
+			var thresholds []string
+			if thresholdRule, ok := rule.(ThresholdRule); ok {
+				thresholds = thresholdRule.Thresholds()
+			}
+			for name := range config.Thresholds {
+				if !contains(thresholds, name) {
+					return fmt.Errorf("threshold %s of rule %s not found", name, id)
+				}
+			}
+		}
+		return nil
+	}
+	if err := validate(c.Rules); err != nil {
+		return err
+	}
+	for _, pattern := range append(c.Images.AllowedRegistries, c.Images.DeniedRegistries...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("registry pattern %s is invalid", pattern)
+		}
+	}
+	for _, format := range c.Secrets.Encryption {
+		if !contains(secretEncryptions, format) && format != SecretEncryptionNone {
+			return fmt.Errorf("secret encryption %s is invalid", format)
+		}
+	}
+	for _, override := range c.Overrides {
+		if err := validate(override.Rules); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Apply enables and disables the rules of the registry as configured.
+func (c *Config) Apply(registry *Registry) error {
+	for id, rule := range c.Rules {
+		if rule.Enabled == nil {
+			continue
+		}
+		var err error
+		if *rule.Enabled {
+			err = registry.Enable(id)
+		} else {
+			err = registry.Disable(id)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rule returns the configuration of the rule for the object with all matching
+// overrides applied. Enabled is only set by overrides, the global state is
+// kept by the registry.
+func (c *Config) Rule(id string, obj *Object) RuleConfig {
+	result := RuleConfig{Thresholds: make(map[string]float64)}
+	if c == nil {
+		return result
+	}
+	result.merge(RuleConfig{Severity: c.Rules[id].Severity, Thresholds: c.Rules[id].Thresholds})
+	for _, override := range c.Overrides {
+		if rule, ok := override.Rules[id]; ok && override.Matches(obj) {
+			result.merge(rule)
+		}
+	}
+	return result
+}
+
+func (r *RuleConfig) merge(other RuleConfig) {
+	if other.Enabled != nil {
+		r.Enabled = other.Enabled
+	}
+	if other.Severity != "" {
+		r.Severity = other.Severity
+	}
+	for name, value := range other.Thresholds {
+		r.Thresholds[name] = value
+	}
+}
+
+// Matches returns true if the object matches the kinds, namespaces and selector of the override.
+func (o *Override) Matches(obj *Object) bool {
+	if len(o.Kinds) > 0 && !contains(o.Kinds, obj.Kind) {
+		return false
+	}
+	if len(o.Namespaces) > 0 && !matchNamespace(o.Namespaces, obj.Namespace) {
+		return false
+	}
+	if o.selector != nil && !o.selector.Matches(labels.Set(obj.Labels)) {
+		return false
+	}
+	return true
+}
+
+func matchNamespace(patterns []string, namespace string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, namespace); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package check_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/seibert-media/k8s-manifest-check/check"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// labeledPod is a pod with placeholders for namespace, team label and cpu limit.
+const labeledPod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: hello-world
+  namespace: %s
+  labels:
+    team: %s
+spec:
+  containers:
+  - name: hello
+    image: "ubuntu:14.04"
+    resources:
+      limits:
+        cpu: %s
+        memory: 100Mi
+      requests:
+        cpu: 100m
+        memory: 100Mi
+`
+
+var _ = Describe("Config", func() {
+	var config *check.Config
+	BeforeEach(func() {
+		var err error
+		config, err = check.ParseConfig([]byte(`rules:
+  cpu-limit-nonzero:
+    enabled: false
+  memory-limit-nonzero:
+    severity: warning
+  cpu-limit-max:
+    thresholds:
+      cores: 2
+overrides:
+- kinds: [Job, CronJob]
+  rules:
+    cpu-limit-nonzero:
+      enabled: true
+- namespaces: ["batch-*"]
+  selector: team=data
+  rules:
+    memory-limit-nonzero:
+      severity: info
+    cpu-limit-max:
+      thresholds:
+        cores: 8
+`))
+		Expect(err).To(BeNil())
+	})
+	It("return error for invalid selector", func() {
+		_, err := check.ParseConfig([]byte("overrides:\n- selector: 'a=b=c'\n"))
+		Expect(err).NotTo(BeNil())
+	})
+	It("validate rules and severities", func() {
+		Expect(config.Validate(check.DefaultRegistry)).To(BeNil())
+		invalid, err := check.ParseConfig([]byte("rules:\n  unknown: {}\n"))
+		Expect(err).To(BeNil())
+		Expect(invalid.Validate(check.DefaultRegistry)).NotTo(BeNil())
+		invalid, err = check.ParseConfig([]byte("rules:\n  cpu-limit-max:\n    severity: fatal\n"))
+		Expect(err).To(BeNil())
+		Expect(invalid.Validate(check.DefaultRegistry)).NotTo(BeNil())
+	})
+	It("return error for unknown fields", func() {
+		_, err := check.ParseConfig([]byte("overides:\n- kinds: [Job]\n"))
+		Expect(err).NotTo(BeNil())
+		_, err = check.ParseConfig([]byte("rules:\n  cpu-limit-max:\n    enable: false\n"))
+		Expect(err).NotTo(BeNil())
+	})
+	It("validate thresholds", func() {
+		invalid, err := check.ParseConfig([]byte("rules:\n  cpu-limit-max:\n    thresholds: {core: 2}\n"))
+		Expect(err).To(BeNil())
+		Expect(invalid.Validate(check.DefaultRegistry)).To(MatchError("threshold core of rule cpu-limit-max not found"))
+		invalid, err = check.ParseConfig([]byte("overrides:\n- rules:\n    cpu-limit-nonzero:\n      thresholds: {cores: 2}\n"))
+		Expect(err).To(BeNil())
+		Expect(invalid.Validate(check.DefaultRegistry)).NotTo(BeNil())
+	})
+	It("enable and disable rules of the registry", func() {
+		registry := check.NewRegistry()
+		registry.Register(&testRule{id: "cpu-limit-nonzero"})
+		Expect(config.Apply(registry)).To(BeNil())
+		Expect(registry.Enabled("cpu-limit-nonzero")).To(BeFalse())
+	})
+	It("apply matching overrides", func() {
+		job := &check.Object{Kind: "Job", Namespace: "default"}
+		Expect(*config.Rule("cpu-limit-nonzero", job).Enabled).To(BeTrue())
+		Expect(config.Rule("cpu-limit-nonzero", &check.Object{Kind: "Pod"}).Enabled).To(BeNil())
+		data := &check.Object{Kind: "Pod", Namespace: "batch-nightly", Labels: map[string]string{"team": "data"}}
+		Expect(config.Rule("memory-limit-nonzero", data).Severity).To(Equal(check.SeverityInfo))
+		Expect(config.Rule("cpu-limit-max", data).Thresholds["cores"]).To(Equal(8.0))
+		web := &check.Object{Kind: "Pod", Namespace: "batch-nightly", Labels: map[string]string{"team": "web"}}
+		Expect(config.Rule("memory-limit-nonzero", web).Severity).To(Equal(check.SeverityWarning))
+		Expect(config.Rule("cpu-limit-max", web).Thresholds["cores"]).To(Equal(2.0))
+	})
+	It("let forced rules take precedence over overrides", func() {
+		registry := check.NewRegistry()
+		rule := &testRule{id: "cpu-limit-nonzero"}
+		registry.Register(rule)
+		checker := &check.Checker{Registry: registry, Config: config}
+		report := &check.Report{}
+		checker.Content(report, "job.yaml", []byte("apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: nightly\n"))
+		job := report.Objects[0]
+		Expect(config.Apply(registry)).To(BeNil())
+		Expect(registry.Active(rule, job)).To(BeTrue())
+		Expect(registry.Force("cpu-limit-nonzero", false)).To(BeNil())
+		Expect(registry.Enabled("cpu-limit-nonzero")).To(BeFalse())
+		Expect(registry.Active(rule, job)).To(BeFalse())
+		Expect(registry.Force("unknown", true)).NotTo(BeNil())
+	})
+	It("find config in parent directories", func() {
+		dir, err := ioutil.TempDir("", "config")
+		Expect(err).To(BeNil())
+		defer os.RemoveAll(dir)
+		Expect(os.MkdirAll(filepath.Join(dir, "a", "b"), 0755)).To(BeNil())
+		Expect(ioutil.WriteFile(filepath.Join(dir, check.ConfigFile), []byte("rules: {}\n"), 0644)).To(BeNil())
+		file, ok := check.FindConfig(filepath.Join(dir, "a", "b"))
+		Expect(ok).To(BeTrue())
+		Expect(file).To(Equal(filepath.Join(dir, check.ConfigFile)))
+		config, err := check.LoadConfig(file)
+		Expect(err).To(BeNil())
+		Expect(config.Rules).To(BeEmpty())
+	})
+	It("use thresholds of overrides when checking", func() {
+		checker := check.New()
+		checker.Registry = check.NewRegistry()
+		for _, rule := range check.DefaultRegistry.Rules() {
+			checker.Registry.Register(rule)
+			if !check.DefaultRegistry.Enabled(rule.ID()) {
+				checker.Registry.Disable(rule.ID())
+			}
+		}
+		Expect(checker.Registry.Enable("cpu-limit-max")).To(BeNil())
+		Expect(config.Apply(checker.Registry)).To(BeNil())
+		checker.Config = config
+		report := &check.Report{}
+		checker.Content(report, "pod.yaml", []byte(fmt.Sprintf(labeledPod, "batch-nightly", "web", "4")))
+		Expect(report.Findings).To(HaveLen(1))
+		Expect(report.Findings[0].Message).To(Equal("cpu limit 4 exceeds maximum of 2 cores"))
+		report = &check.Report{}
+		checker.Content(report, "pod.yaml", []byte(fmt.Sprintf(labeledPod, "batch-nightly", "data", "4")))
+		Expect(report.Valid()).To(BeTrue())
+	})
+})
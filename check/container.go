@@ -0,0 +1,42 @@
+package check
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ContainerType names the list of the pod spec a container is defined in.
+type ContainerType string
+
+const (
+	InitContainerType    ContainerType = "initContainer"
+	RegularContainerType ContainerType = "container"
+)
+
+// Field returns the name of the pod spec field containing containers of the type.
+func (c ContainerType) Field() string {
+	return string(c) + "s"
+}
+
+// Container is a container of a pod spec together with the list it is defined in.
+type Container struct {
+	corev1.Container
+	Type ContainerType
+	// Index of the container in the list of its type.
+	Index int
+	// Path is the field path of the container in its object, it is only set by Object.Containers.
+	Path *field.Path
+}
+
+// Containers returns the init containers followed by the containers of the pod spec.
+// Ephemeral containers do not exist in the vendored API versions and are therefore not returned.
+func Containers(spec corev1.PodSpec) []Container {
+	var result []Container
+	for i, container := range spec.InitContainers {
+		result = append(result, Container{Container: container, Type: InitContainerType, Index: i})
+	}
+	for i, container := range spec.Containers {
+		result = append(result, Container{Container: container, Type: RegularContainerType, Index: i})
+	}
+	return result
+}
@@ -0,0 +1,139 @@
+package check
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	k8s_yaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// customResourceDefinition is the part of a CustomResourceDefinition of
+// apiextensions.k8s.io/v1 or v1beta1 describing the schemas of its versions.
+type customResourceDefinition struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Spec       struct {
+		Group string `json:"group"`
+		Names struct {
+			Kind string `json:"kind"`
+		} `json:"names"`
+		// Version and Validation are only used by v1beta1.
+		Version    string                    `json:"version"`
+		Validation *customResourceValidation `json:"validation"`
+		// PreserveUnknownFields defaults to true in v1beta1 and is false in v1.
+		PreserveUnknownFields *bool `json:"preserveUnknownFields"`
+		Versions              []struct {
+			Name   string                    `json:"name"`
+			Schema *customResourceValidation `json:"schema"`
+		} `json:"versions"`
+	} `json:"spec"`
+}
+
+type customResourceValidation struct {
+	OpenAPIV3Schema *Schema `json:"openAPIV3Schema"`
+}
+
+// LoadCRDs reads the CustomResourceDefinitions of the manifests at the given
+// paths and adds their schemas. Directories are searched recursively for
+// manifest files, documents of other kinds are ignored.
+func (s *Schemas) LoadCRDs(paths ...string) error {
+	for _, path := range paths {
+		err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || (file != path && !isManifest(file)) {
+				return nil
+			}
+			content, err := ioutil.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			if err := s.AddCRDs(content); err != nil {
+				return fmt.Errorf("%v in %s", err, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("load crds failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// AddCRDs adds the schemas of all CustomResourceDefinitions of the YAML documents.
+func (s *Schemas) AddCRDs(content []byte) error {
+	reader := k8s_yaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(content)))
+	for {
+		document, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read document failed: %v", err)
+		}
+		crd := &customResourceDefinition{}
+		if err := yaml.Unmarshal(document, crd); err != nil {
+			return fmt.Errorf("parse crd failed: %v", err)
+		}
+		if crd.Kind != "CustomResourceDefinition" {
+			continue
+		}
+		s.addCRD(crd)
+	}
+}
+
+func (s *Schemas) addCRD(crd *customResourceDefinition) {
+	strict := crd.Spec.PreserveUnknownFields != nil && !*crd.Spec.PreserveUnknownFields
+	if crd.APIVersion == "apiextensions.k8s.io/v1" {
+		strict = true
+	}
+	add := func(version string, validation *customResourceValidation) {
+		gvk := schemaGroupVersionKind{Group: crd.Spec.Group, Version: version, Kind: crd.Spec.Names.Kind}
+		root := &schemaRoot{schema: &Schema{Type: "object"}, strict: strict}
+		if validation != nil && validation.OpenAPIV3Schema != nil {
+			root.schema = withObjectMeta(validation.OpenAPIV3Schema)
+			// the API server keeps unknown fields unless they are pruned
+			root.schema.PreserveUnknown = !strict
+		} else {
+			root.strict = false
+		}
+		s.kinds[gvk] = root
+	}
+	if crd.Spec.Version != "" && len(crd.Spec.Versions) == 0 {
+		add(crd.Spec.Version, crd.Spec.Validation)
+	}
+	for _, version := range crd.Spec.Versions {
+		if version.Schema != nil {
+			add(version.Name, version.Schema)
+		} else {
+			add(version.Name, crd.Spec.Validation)
+		}
+	}
+}
+
+// withObjectMeta returns a copy of the schema of a custom resource, which
+// defines the fields every object has.
+func withObjectMeta(schema *Schema) *Schema {
+	result := *schema
+	result.Properties = make(map[string]*Schema)
+	for name, property := range schema.Properties {
+		result.Properties[name] = property
+	}
+	for name, property := range map[string]*Schema{
+		"apiVersion": {Type: "string"},
+		"kind":       {Type: "string"},
+		"metadata":   {Type: "object", PreserveUnknown: true},
+	} {
+		if _, ok := result.Properties[name]; !ok {
+			result.Properties[name] = property
+		}
+	}
+	return &result
+}
@@ -0,0 +1,179 @@
+package check_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/seibert-media/k8s-manifest-check/check"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const certificateCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: certificates.cert-manager.io
+spec:
+  group: cert-manager.io
+  names:
+    kind: Certificate
+    plural: certificates
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            required: ["secretName"]
+            properties:
+              secretName:
+                type: string
+              dnsNames:
+                type: array
+                items:
+                  type: string
+              duration:
+                type: string
+              issuerRef:
+                type: object
+                x-kubernetes-preserve-unknown-fields: true
+`
+
+const backupCRD = `apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: backups.example.com
+spec:
+  group: example.com
+  version: v1alpha1
+  names:
+    kind: Backup
+  validation:
+    openAPIV3Schema:
+      properties:
+        spec:
+          properties:
+            schedule:
+              type: string
+              pattern: "^@(daily|weekly)$"
+`
+
+var _ = Describe("CRDs", func() {
+	var checker *check.Checker
+	validate := func(content string) []string {
+		report := &check.Report{}
+		checker.Content(report, "manifest.yaml", []byte(content))
+		var findings []string
+		for _, finding := range report.Findings {
+			findings = append(findings, finding.String())
+		}
+		return findings
+	}
+	BeforeEach(func() {
+		checker = check.New()
+		checker.Schemas = check.NewSchemas()
+		Expect(checker.Schemas.AddCRDs([]byte(certificateCRD + "---\n" + backupCRD))).To(BeNil())
+		Expect(checker.Schemas.Len()).To(Equal(2))
+	})
+	It("fail to parse custom resources without CRD", func() {
+		checker.Schemas = nil
+		Expect(validate(`apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: web
+`)).To(Equal([]string{"parse content failed in manifest.yaml:1 (document 1) [parse]"}))
+	})
+	It("check CRDs together with their custom resources", func() {
+		Expect(validate(certificateCRD + `---
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: web
+spec:
+  dnsNames: ["example.com"]
+`)).To(Equal([]string{"spec.secretName is required in manifest.yaml:39:1 (document 2, Certificate web) [openapi-schema]"}))
+	})
+	It("report nothing for valid custom resources", func() {
+		Expect(validate(`apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: web
+  namespace: default
+  labels:
+    app: web
+spec:
+  secretName: web-tls
+  dnsNames:
+  - example.com
+  issuerRef:
+    name: letsencrypt
+    kind: ClusterIssuer
+`)).To(BeEmpty())
+	})
+	It("report violations of the CRD schema", func() {
+		Expect(validate(`apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: web
+spec:
+  dnsNames: example.com
+  duraton: 24h
+`)).To(Equal([]string{
+			"spec.secretName is required in manifest.yaml:5:1 (document 1, Certificate web) [openapi-schema]",
+			"spec.dnsNames must be of type array in manifest.yaml:6:3 (document 1, Certificate web) [openapi-schema]",
+			"spec.duraton is not defined by the schema in manifest.yaml:7:3 (document 1, Certificate web) [openapi-schema]",
+		}))
+	})
+	It("keep unknown fields of v1beta1 CRDs", func() {
+		Expect(validate(`apiVersion: example.com/v1alpha1
+kind: Backup
+metadata:
+  name: nightly
+spec:
+  schedule: "@hourly"
+  retention: 7
+`)).To(Equal([]string{
+			"spec.schedule must match the pattern ^@(daily|weekly)$ in manifest.yaml:6:3 (document 1, Backup nightly) [openapi-schema]",
+		}))
+	})
+	It("report unknown fields of custom resources once in strict mode", func() {
+		schemas := checker.Schemas
+		checker = checkerWithRules(check.UnknownFieldRule, check.SchemaRule)
+		checker.Schemas = schemas
+		Expect(validate(`apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: web
+spec:
+  secretName: web-tls
+  duraton: 24h
+---
+apiVersion: example.com/v1alpha1
+kind: Backup
+metadata:
+  name: nightly
+spec:
+  retention: 7
+`)).To(Equal([]string{
+			"spec.duraton is not defined by the schema in manifest.yaml:7:3 (document 1, Certificate web) [openapi-schema]",
+		}))
+	})
+	It("skip other documents and load directories", func() {
+		dir, err := ioutil.TempDir("", "crds")
+		Expect(err).To(BeNil())
+		defer os.RemoveAll(dir)
+		Expect(ioutil.WriteFile(filepath.Join(dir, "certificate.yaml"), []byte(validPod+"---\n"+certificateCRD), 0644)).To(BeNil())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("# crds"), 0644)).To(BeNil())
+		schemas := check.NewSchemas()
+		Expect(schemas.LoadCRDs(dir)).To(BeNil())
+		Expect(schemas.Len()).To(Equal(1))
+		Expect(schemas.Has("cert-manager.io/v1", "Certificate")).To(BeTrue())
+		Expect(schemas.Has("apps/v1", "Deployment")).To(BeFalse())
+	})
+})
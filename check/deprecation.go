@@ -0,0 +1,123 @@
+package check
+
+import (
+	"fmt"
+)
+
+func init() {
+	Register(&deprecationRule{})
+}
+
+// deprecatedAPI is an API version of a kind, which is deprecated and removed
+// in later Kubernetes releases.
+type deprecatedAPI struct {
+	apiVersion string
+	kinds      []string
+	deprecated KubernetesVersion
+	// removed is the zero version if the removal is not scheduled.
+	removed KubernetesVersion
+	// replacement is the API version to use instead, it is empty if there is no replacement.
+	replacement string
+}
+
+// deprecatedAPIs are the API versions removed up to Kubernetes 1.32 by the
+// deprecated API migration guide of Kubernetes.
+var deprecatedAPIs = []deprecatedAPI{
+	{apiVersion: "extensions/v1beta1", kinds: []string{"Deployment", "DaemonSet", "ReplicaSet"}, deprecated: KubernetesVersion{1, 9}, removed: KubernetesVersion{1, 16}, replacement: "apps/v1"},
+	{apiVersion: "extensions/v1beta1", kinds: []string{"NetworkPolicy"}, deprecated: KubernetesVersion{1, 9}, removed: KubernetesVersion{1, 16}, replacement: "networking.k8s.io/v1"},
+	{apiVersion: "extensions/v1beta1", kinds: []string{"PodSecurityPolicy"}, deprecated: KubernetesVersion{1, 10}, removed: KubernetesVersion{1, 16}, replacement: "policy/v1beta1"},
+	{apiVersion: "extensions/v1beta1", kinds: []string{"Ingress"}, deprecated: KubernetesVersion{1, 14}, removed: KubernetesVersion{1, 22}, replacement: "networking.k8s.io/v1"},
+	{apiVersion: "apps/v1beta1", kinds: []string{"Deployment", "StatefulSet"}, deprecated: KubernetesVersion{1, 9}, removed: KubernetesVersion{1, 16}, replacement: "apps/v1"},
+	{apiVersion: "apps/v1beta2", kinds: []string{"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet"}, deprecated: KubernetesVersion{1, 9}, removed: KubernetesVersion{1, 16}, replacement: "apps/v1"},
+	{apiVersion: "batch/v2alpha1", kinds: []string{"CronJob"}, deprecated: KubernetesVersion{1, 8}, removed: KubernetesVersion{1, 21}, replacement: "batch/v1"},
+	{apiVersion: "batch/v1beta1", kinds: []string{"CronJob"}, deprecated: KubernetesVersion{1, 21}, removed: KubernetesVersion{1, 25}, replacement: "batch/v1"},
+	{apiVersion: "policy/v1beta1", kinds: []string{"PodDisruptionBudget"}, deprecated: KubernetesVersion{1, 21}, removed: KubernetesVersion{1, 25}, replacement: "policy/v1"},
+	{apiVersion: "policy/v1beta1", kinds: []string{"PodSecurityPolicy"}, deprecated: KubernetesVersion{1, 21}, removed: KubernetesVersion{1, 25}},
+	{apiVersion: "rbac.authorization.k8s.io/v1alpha1", kinds: []string{"ClusterRole", "ClusterRoleBinding", "Role", "RoleBinding"}, deprecated: KubernetesVersion{1, 8}, removed: KubernetesVersion{1, 22}, replacement: "rbac.authorization.k8s.io/v1"},
+	{apiVersion: "rbac.authorization.k8s.io/v1beta1", kinds: []string{"ClusterRole", "ClusterRoleBinding", "Role", "RoleBinding"}, deprecated: KubernetesVersion{1, 17}, removed: KubernetesVersion{1, 22}, replacement: "rbac.authorization.k8s.io/v1"},
+	{apiVersion: "networking.k8s.io/v1beta1", kinds: []string{"Ingress", "IngressClass"}, deprecated: KubernetesVersion{1, 19}, removed: KubernetesVersion{1, 22}, replacement: "networking.k8s.io/v1"},
+	{apiVersion: "apiextensions.k8s.io/v1beta1", kinds: []string{"CustomResourceDefinition"}, deprecated: KubernetesVersion{1, 16}, removed: KubernetesVersion{1, 22}, replacement: "apiextensions.k8s.io/v1"},
+	{apiVersion: "admissionregistration.k8s.io/v1beta1", kinds: []string{"MutatingWebhookConfiguration", "ValidatingWebhookConfiguration"}, deprecated: KubernetesVersion{1, 16}, removed: KubernetesVersion{1, 22}, replacement: "admissionregistration.k8s.io/v1"},
+	{apiVersion: "apiregistration.k8s.io/v1beta1", kinds: []string{"APIService"}, deprecated: KubernetesVersion{1, 19}, removed: KubernetesVersion{1, 22}, replacement: "apiregistration.k8s.io/v1"},
+	{apiVersion: "scheduling.k8s.io/v1alpha1", kinds: []string{"PriorityClass"}, deprecated: KubernetesVersion{1, 11}, removed: KubernetesVersion{1, 22}, replacement: "scheduling.k8s.io/v1"},
+	{apiVersion: "scheduling.k8s.io/v1beta1", kinds: []string{"PriorityClass"}, deprecated: KubernetesVersion{1, 14}, removed: KubernetesVersion{1, 22}, replacement: "scheduling.k8s.io/v1"},
+	{apiVersion: "storage.k8s.io/v1beta1", kinds: []string{"CSIDriver", "CSINode", "StorageClass", "VolumeAttachment"}, deprecated: KubernetesVersion{1, 19}, removed: KubernetesVersion{1, 22}, replacement: "storage.k8s.io/v1"},
+	{apiVersion: "coordination.k8s.io/v1beta1", kinds: []string{"Lease"}, deprecated: KubernetesVersion{1, 19}, removed: KubernetesVersion{1, 22}, replacement: "coordination.k8s.io/v1"},
+	{apiVersion: "certificates.k8s.io/v1beta1", kinds: []string{"CertificateSigningRequest"}, deprecated: KubernetesVersion{1, 19}, removed: KubernetesVersion{1, 22}, replacement: "certificates.k8s.io/v1"},
+	{apiVersion: "authentication.k8s.io/v1beta1", kinds: []string{"TokenReview"}, deprecated: KubernetesVersion{1, 19}, removed: KubernetesVersion{1, 22}, replacement: "authentication.k8s.io/v1"},
+	{apiVersion: "authorization.k8s.io/v1beta1", kinds: []string{"LocalSubjectAccessReview", "SelfSubjectAccessReview", "SubjectAccessReview"}, deprecated: KubernetesVersion{1, 19}, removed: KubernetesVersion{1, 22}, replacement: "authorization.k8s.io/v1"},
+	{apiVersion: "autoscaling/v2beta1", kinds: []string{"HorizontalPodAutoscaler"}, deprecated: KubernetesVersion{1, 22}, removed: KubernetesVersion{1, 25}, replacement: "autoscaling/v2"},
+	{apiVersion: "autoscaling/v2beta2", kinds: []string{"HorizontalPodAutoscaler"}, deprecated: KubernetesVersion{1, 23}, removed: KubernetesVersion{1, 26}, replacement: "autoscaling/v2"},
+	{apiVersion: "discovery.k8s.io/v1beta1", kinds: []string{"EndpointSlice"}, deprecated: KubernetesVersion{1, 21}, removed: KubernetesVersion{1, 25}, replacement: "discovery.k8s.io/v1"},
+	{apiVersion: "events.k8s.io/v1beta1", kinds: []string{"Event"}, deprecated: KubernetesVersion{1, 19}, removed: KubernetesVersion{1, 25}, replacement: "events.k8s.io/v1"},
+	{apiVersion: "node.k8s.io/v1beta1", kinds: []string{"RuntimeClass"}, deprecated: KubernetesVersion{1, 20}, removed: KubernetesVersion{1, 25}, replacement: "node.k8s.io/v1"},
+	{apiVersion: "flowcontrol.apiserver.k8s.io/v1beta1", kinds: []string{"FlowSchema", "PriorityLevelConfiguration"}, deprecated: KubernetesVersion{1, 23}, removed: KubernetesVersion{1, 26}, replacement: "flowcontrol.apiserver.k8s.io/v1"},
+	{apiVersion: "storage.k8s.io/v1beta1", kinds: []string{"CSIStorageCapacity"}, deprecated: KubernetesVersion{1, 24}, removed: KubernetesVersion{1, 27}, replacement: "storage.k8s.io/v1"},
+	{apiVersion: "flowcontrol.apiserver.k8s.io/v1beta2", kinds: []string{"FlowSchema", "PriorityLevelConfiguration"}, deprecated: KubernetesVersion{1, 26}, removed: KubernetesVersion{1, 29}, replacement: "flowcontrol.apiserver.k8s.io/v1"},
+	{apiVersion: "flowcontrol.apiserver.k8s.io/v1beta3", kinds: []string{"FlowSchema", "PriorityLevelConfiguration"}, deprecated: KubernetesVersion{1, 29}, removed: KubernetesVersion{1, 32}, replacement: "flowcontrol.apiserver.k8s.io/v1"},
+}
+
+// findDeprecatedAPI returns the deprecation of the API version and kind.
+func findDeprecatedAPI(apiVersion, kind string) (deprecatedAPI, bool) {
+	for _, api := range deprecatedAPIs {
+		if api.apiVersion == apiVersion && contains(api.kinds, kind) {
+			return api, true
+		}
+	}
+	return deprecatedAPI{}, false
+}
+
+// knownAPI returns true if the API version of the kind is deprecated or the
+// replacement of a deprecated API version.
+func knownAPI(apiVersion, kind string) bool {
+	for _, api := range deprecatedAPIs {
+		if (api.apiVersion == apiVersion || api.replacement == apiVersion) && contains(api.kinds, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// deprecationRule reports API versions deprecated or removed in the target
+// Kubernetes version of the config. Without target version every deprecated
+// API version is reported.
+type deprecationRule struct{}
+
+func (d *deprecationRule) ID() string {
+	return "deprecated-api-version"
+}
+
+func (d *deprecationRule) Description() string {
+	return "apiVersion of every object is neither deprecated nor removed in the target Kubernetes version"
+}
+
+func (d *deprecationRule) Severity() Severity {
+	return SeverityWarning
+}
+
+func (d *deprecationRule) Check(obj *Object) []Finding {
+	api, ok := findDeprecatedAPI(obj.APIVersion, obj.Kind)
+	if !ok {
+		return nil
+	}
+	target, hasTarget := obj.config.TargetVersion()
+	finding := Finding{Field: "apiVersion", Severity: SeverityWarning}
+	switch {
+	case hasTarget && api.removed != (KubernetesVersion{}) && target.AtLeast(api.removed):
+		finding.Severity = SeverityError
+		finding.Message = fmt.Sprintf("%s %s is removed in Kubernetes %s", obj.APIVersion, obj.Kind, api.removed)
+	case !hasTarget || target.AtLeast(api.deprecated):
+		finding.Message = fmt.Sprintf("%s %s is deprecated since Kubernetes %s", obj.APIVersion, obj.Kind, api.deprecated)
+		if api.removed != (KubernetesVersion{}) {
+			finding.Message = fmt.Sprintf("%s and removed in %s", finding.Message, api.removed)
+		}
+	default:
+		return nil
+	}
+	if api.replacement != "" {
+		finding.Message = fmt.Sprintf("%s, use %s", finding.Message, api.replacement)
+	} else {
+		finding.Message = fmt.Sprintf("%s without replacement", finding.Message)
+	}
+	return []Finding{finding}
+}
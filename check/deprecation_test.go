@@ -0,0 +1,156 @@
+package check_test
+
+import (
+	"github.com/seibert-media/k8s-manifest-check/check"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Deprecation", func() {
+	const deployment = `apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: "ubuntu:14.04"
+        resources:
+          limits:
+            cpu: 100m
+            memory: 100Mi
+          requests:
+            cpu: 100m
+            memory: 100Mi
+`
+	checkVersion := func(version string) []check.Finding {
+		checker := check.New()
+		if version != "" {
+			checker.Config = &check.Config{}
+			Expect(checker.Config.SetKubernetesVersion(version)).To(BeNil())
+		}
+		report := &check.Report{}
+		checker.Content(report, "deploy.yaml", []byte(deployment))
+		return report.Findings
+	}
+	It("report deprecated api version without target version", func() {
+		findings := checkVersion("")
+		Expect(findings).To(HaveLen(1))
+		Expect(findings[0].String()).To(Equal("extensions/v1beta1 Deployment is deprecated since Kubernetes 1.9 and removed in 1.16, use apps/v1 in deploy.yaml:1:1 (document 1, Deployment web) [deprecated-api-version]"))
+		Expect(findings[0].Severity).To(Equal(check.SeverityWarning))
+	})
+	It("report nothing before deprecation", func() {
+		Expect(checkVersion("1.8")).To(BeEmpty())
+	})
+	It("report deprecated api version as warning", func() {
+		findings := checkVersion("v1.15.3")
+		Expect(findings).To(HaveLen(1))
+		Expect(findings[0].Severity).To(Equal(check.SeverityWarning))
+	})
+	It("report removed api version as error", func() {
+		findings := checkVersion("1.16")
+		Expect(findings).To(HaveLen(1))
+		Expect(findings[0].Message).To(Equal("extensions/v1beta1 Deployment is removed in Kubernetes 1.16, use apps/v1"))
+		Expect(findings[0].Severity).To(Equal(check.SeverityError))
+	})
+	It("report removed api version missing in the vendored api", func() {
+		checker := check.New()
+		checker.Config = &check.Config{}
+		Expect(checker.Config.SetKubernetesVersion("1.26")).To(BeNil())
+		report := &check.Report{}
+		checker.Content(report, "hpa.yaml", []byte("apiVersion: autoscaling/v2beta2\nkind: HorizontalPodAutoscaler\nmetadata:\n  name: web\n"))
+		Expect(report.Findings).To(HaveLen(1))
+		Expect(report.Findings[0].String()).To(Equal("autoscaling/v2beta2 HorizontalPodAutoscaler is removed in Kubernetes 1.26, use autoscaling/v2 in hpa.yaml:1:1 (document 1, HorizontalPodAutoscaler web) [deprecated-api-version]"))
+		Expect(report.Findings[0].Severity).To(Equal(check.SeverityError))
+	})
+	It("report api versions removed up to kubernetes 1.32", func() {
+		checker := check.New()
+		checker.Config = &check.Config{}
+		Expect(checker.Config.SetKubernetesVersion("1.29")).To(BeNil())
+		report := &check.Report{}
+		checker.Content(report, "cluster.yaml", []byte(`apiVersion: flowcontrol.apiserver.k8s.io/v1beta1
+kind: FlowSchema
+metadata:
+  name: service-accounts
+---
+apiVersion: flowcontrol.apiserver.k8s.io/v1beta2
+kind: PriorityLevelConfiguration
+metadata:
+  name: workload-low
+---
+apiVersion: flowcontrol.apiserver.k8s.io/v1beta3
+kind: FlowSchema
+metadata:
+  name: probes
+---
+apiVersion: storage.k8s.io/v1beta1
+kind: CSIStorageCapacity
+metadata:
+  name: zone-a
+`))
+		var findings []string
+		for _, finding := range report.Findings {
+			findings = append(findings, finding.String())
+		}
+		Expect(findings).To(Equal([]string{
+			"flowcontrol.apiserver.k8s.io/v1beta1 FlowSchema is removed in Kubernetes 1.26, use flowcontrol.apiserver.k8s.io/v1 in cluster.yaml:1:1 (document 1, FlowSchema service-accounts) [deprecated-api-version]",
+			"flowcontrol.apiserver.k8s.io/v1beta2 PriorityLevelConfiguration is removed in Kubernetes 1.29, use flowcontrol.apiserver.k8s.io/v1 in cluster.yaml:6:1 (document 2, PriorityLevelConfiguration workload-low) [deprecated-api-version]",
+			"flowcontrol.apiserver.k8s.io/v1beta3 FlowSchema is deprecated since Kubernetes 1.29 and removed in 1.32, use flowcontrol.apiserver.k8s.io/v1 in cluster.yaml:11:1 (document 3, FlowSchema probes) [deprecated-api-version]",
+			"storage.k8s.io/v1beta1 CSIStorageCapacity is removed in Kubernetes 1.27, use storage.k8s.io/v1 in cluster.yaml:16:1 (document 4, CSIStorageCapacity zone-a) [deprecated-api-version]",
+		}))
+	})
+	It("accept replacement api versions missing in the vendored api", func() {
+		checker := check.New()
+		checker.Config = &check.Config{}
+		Expect(checker.Config.SetKubernetesVersion("1.26")).To(BeNil())
+		report := &check.Report{}
+		checker.Content(report, "hpa.yaml", []byte("apiVersion: autoscaling/v2\nkind: HorizontalPodAutoscaler\nmetadata:\n  name: web\n---\napiVersion: policy/v1\nkind: PodDisruptionBudget\nmetadata:\n  name: web\n"))
+		Expect(report.Findings).To(BeEmpty())
+		Expect(report.Objects).To(HaveLen(2))
+	})
+	It("check pod templates of batch/v1 cron jobs", func() {
+		report := &check.Report{}
+		check.Content(report, "cronjob.yaml", []byte(`apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: nightly
+spec:
+  schedule: "0 0 * * *"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: app
+            image: "ubuntu:14.04"
+            resources:
+              limits:
+                cpu: 100m
+                memory: 100Mi
+              requests:
+                memory: 100Mi
+`))
+		Expect(report.Findings).To(HaveLen(1))
+		Expect(report.Findings[0].String()).To(Equal("cpu request is zero in cronjob.yaml:18:15 (document 1, CronJob nightly, container app) [cpu-request-nonzero]"))
+	})
+})
+
+var _ = Describe("KubernetesVersion", func() {
+	It("parse versions with and without prefix and patch", func() {
+		for _, value := range []string{"1.22", "v1.22", "v1.22.3", "1.22.0-rc.1"} {
+			version, err := check.ParseKubernetesVersion(value)
+			Expect(err).To(BeNil())
+			Expect(version).To(Equal(check.KubernetesVersion{Major: 1, Minor: 22}))
+		}
+	})
+	It("return error for invalid version", func() {
+		_, err := check.ParseKubernetesVersion("latest")
+		Expect(err).NotTo(BeNil())
+	})
+	It("compare minor versions numerically", func() {
+		Expect(check.KubernetesVersion{Major: 1, Minor: 10}.AtLeast(check.KubernetesVersion{Major: 1, Minor: 9})).To(BeTrue())
+		Expect(check.KubernetesVersion{Major: 1, Minor: 9}.AtLeast(check.KubernetesVersion{Major: 1, Minor: 10})).To(BeFalse())
+	})
+})
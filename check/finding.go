@@ -0,0 +1,174 @@
+package check
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// ReadRule is reported if a manifest can not be read.
+	ReadRule = "read"
+	// ParseRule is reported if a manifest or one of its documents can not be parsed.
+	ParseRule = "parse"
+)
+
+// Finding describes a single problem found in a manifest.
+type Finding struct {
+	File string
+	// Document is the index of the document in the file starting with 1.
+	// It is 0 if the finding applies to the whole file.
+	Document int
+	// Line and Column of the offending field in the file, both are 0 if unknown.
+	Line          int
+	Column        int
+	APIVersion    string
+	Kind          string
+	Namespace     string
+	Name          string
+	ContainerType ContainerType
+	Container     string
+	// Field is the path of the offending field in the object, e.g. "spec.containers[0].resources".
+	Field    string
+	Rule     string
+	Severity Severity
+	Message  string
+	// Justification is the reason given for a suppressed finding.
+	Justification string
+}
+
+// String returns the finding in the form
+// "<message> in <file>:<line>:<column> (<context>) [<rule>]".
+func (f Finding) String() string {
+	result := fmt.Sprintf("%s in %s", f.Message, f.Location())
+	if context := f.Context(); context != "" {
+		result = fmt.Sprintf("%s (%s)", result, context)
+	}
+	if f.Rule != "" {
+		result = fmt.Sprintf("%s [%s]", result, f.Rule)
+	}
+	return result
+}
+
+// Location returns the file followed by line and column if known.
+func (f Finding) Location() string {
+	if f.Line == 0 {
+		return f.File
+	}
+	if f.Column == 0 {
+		return fmt.Sprintf("%s:%d", f.File, f.Line)
+	}
+	return fmt.Sprintf("%s:%d:%d", f.File, f.Line, f.Column)
+}
+
+// Context describes where in the file the finding applies to in the form
+// "document <n>, <kind> <namespace>/<name>, <container type> <container>".
+// Unknown parts are left out.
+func (f Finding) Context() string {
+	var details []string
+	if f.Document > 0 {
+		details = append(details, fmt.Sprintf("document %d", f.Document))
+	}
+	if f.Kind != "" {
+		details = append(details, fmt.Sprintf("%s %s", f.Kind, f.Object()))
+	}
+	if f.Container != "" {
+		details = append(details, fmt.Sprintf("%s %s", f.ContainerType, f.Container))
+	}
+	return strings.Join(details, ", ")
+}
+
+// Object returns the name of the object prefixed with its namespace if set.
+func (f Finding) Object() string {
+	return qualifiedName(f.Namespace, f.Name)
+}
+
+func qualifiedName(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
+
+// Report collects the findings of all checked manifests.
+type Report struct {
+	// Files contains all checked files in the order they were checked.
+	Files []string
+	// Objects contains all checked objects in the order they were checked.
+	Objects  []*Object
+	Findings []Finding
+	// Suppressed contains the findings suppressed by annotations, they do not affect the result.
+	Suppressed []Finding
+	// Baselined contains the findings known by the baseline, they do not affect the result.
+	Baselined []Finding
+}
+
+// Add appends the given findings to the report.
+func (r *Report) Add(findings ...Finding) {
+	r.Findings = append(r.Findings, findings...)
+}
+
+// Merge appends the files, objects and findings of the other report.
+func (r *Report) Merge(other *Report) {
+	r.Files = append(r.Files, other.Files...)
+	r.Objects = append(r.Objects, other.Objects...)
+	r.Findings = append(r.Findings, other.Findings...)
+	r.Suppressed = append(r.Suppressed, other.Suppressed...)
+	r.Baselined = append(r.Baselined, other.Baselined...)
+}
+
+// sort orders the findings and suppressed findings by file in the order the
+// files were checked and by document. The order of the findings of a
+// document is kept.
+func (r *Report) sort() {
+	files := make(map[string]int)
+	for i, file := range r.Files {
+		if _, ok := files[file]; !ok {
+			files[file] = i
+		}
+	}
+	for _, findings := range [][]Finding{r.Findings, r.Suppressed} {
+		findings := findings
+		sort.SliceStable(findings, func(i, j int) bool {
+			a, aok := files[findings[i].File]
+			b, bok := files[findings[j].File]
+			if aok != bok || a != b {
+				return aok && (!bok || a < b)
+			}
+			return findings[i].Document < findings[j].Document
+		})
+	}
+}
+
+// Suppress appends the given suppressed findings to the report.
+func (r *Report) Suppress(findings ...Finding) {
+	r.Suppressed = append(r.Suppressed, findings...)
+}
+
+// Count returns the number of findings with the given severity.
+func (r *Report) Count(severity Severity) int {
+	count := 0
+	for _, finding := range r.Findings {
+		if finding.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// Valid returns true if no findings were reported.
+func (r *Report) Valid() bool {
+	return len(r.Findings) == 0
+}
+
+// Max returns the highest severity of all findings or an empty severity if
+// there are no findings.
+func (r *Report) Max() Severity {
+	var result Severity
+	for _, finding := range r.Findings {
+		if finding.Severity.level() > result.level() {
+			result = finding.Severity
+		}
+	}
+	return result
+}
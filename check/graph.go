@@ -0,0 +1,60 @@
+package check
+
+// Graph indexes all objects checked in one run to resolve references between
+// them. Objects without namespace are applied to the namespace of the context
+// and therefore resolve references from every namespace and vice versa.
+type Graph struct {
+	kinds map[string][]*Object
+}
+
+// NewGraph returns the graph of the given objects. SealedSecrets are
+// indexed as the Secrets they are unsealed to.
+func NewGraph(objects []*Object) *Graph {
+	result := &Graph{kinds: make(map[string][]*Object)}
+	for _, obj := range objects {
+		kind := obj.Kind
+		if isSealedSecret(obj) {
+			kind = "Secret"
+		}
+		result.kinds[kind] = append(result.kinds[kind], obj)
+	}
+	return result
+}
+
+// Lookup returns the object of the kind with the name in the namespace.
+func (g *Graph) Lookup(kind, namespace, name string) (*Object, bool) {
+	for _, obj := range g.Objects(kind, namespace) {
+		if obj.Name == name {
+			return obj, true
+		}
+	}
+	return nil, false
+}
+
+// Objects returns all objects of the kind in the namespace.
+func (g *Graph) Objects(kind, namespace string) []*Object {
+	var result []*Object
+	for _, obj := range g.kinds[kind] {
+		if sameNamespace(obj.Namespace, namespace) {
+			result = append(result, obj)
+		}
+	}
+	return result
+}
+
+// Workloads returns all objects with a pod template in the namespace.
+func (g *Graph) Workloads(namespace string) []*Object {
+	var result []*Object
+	for _, objects := range g.kinds {
+		for _, obj := range objects {
+			if obj.Template != nil && sameNamespace(obj.Namespace, namespace) {
+				result = append(result, obj)
+			}
+		}
+	}
+	return result
+}
+
+func sameNamespace(a, b string) bool {
+	return a == "" || b == "" || a == b
+}
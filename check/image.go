@@ -0,0 +1,196 @@
+package check
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Rule IDs of the image checks.
+const (
+	ImageReferenceRule = "image-reference"
+	ImageTagRule       = "image-latest-tag"
+	ImageDigestRule    = "image-digest"
+	ImageRegistryRule  = "image-registry"
+)
+
+func init() {
+	for _, rule := range imageRules {
+		Register(rule)
+	}
+	DefaultRegistry.Disable(ImageDigestRule)
+}
+
+// DefaultImageRegistry is the registry of images without registry.
+const DefaultImageRegistry = "docker.io"
+
+var (
+	domainComponent = `(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])`
+	pathComponent   = `[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*`
+	// imageReferenceRegexp matches the image reference grammar of the
+	// Docker distribution project with the name, tag and digest as groups.
+	imageReferenceRegexp = regexp.MustCompile(`^((?:` + domainComponent + `(?:\.` + domainComponent + `)*(?::[0-9]+)?/)?` +
+		pathComponent + `(?:/` + pathComponent + `)*)` +
+		`(?::([\w][\w.-]{0,127}))?` +
+		`(?:@([A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,}))?$`)
+)
+
+// ImageReference is a parsed container image reference.
+type ImageReference struct {
+	// Registry is the host of the registry, DefaultImageRegistry if the reference has none.
+	Registry string
+	// Repository is the path of the image in the registry like "library/nginx".
+	Repository string
+	// Tag is empty if the reference has no tag.
+	Tag string
+	// Digest is empty if the reference is not pinned by digest.
+	Digest string
+}
+
+// ParseImageReference parses the image reference of a container like
+// "registry.example.com:5000/team/app:1.0@sha256:...".
+func ParseImageReference(image string) (ImageReference, error) {
+	match := imageReferenceRegexp.FindStringSubmatch(image)
+	if match == nil || len(match[1]) > 255 {
+		return ImageReference{}, fmt.Errorf("image reference %s is invalid", image)
+	}
+	result := ImageReference{Registry: DefaultImageRegistry, Repository: match[1], Tag: match[2], Digest: match[3]}
+	if i := strings.Index(match[1], "/"); i >= 0 {
+		domain := match[1][:i]
+		if strings.ContainsAny(domain, ".:") || domain == "localhost" {
+			result.Registry = domain
+			result.Repository = match[1][i+1:]
+		}
+	}
+	if result.Registry == DefaultImageRegistry && !strings.Contains(result.Repository, "/") {
+		result.Repository = "library/" + result.Repository
+	}
+	return result, nil
+}
+
+// Name returns the registry and repository of the image.
+func (r ImageReference) Name() string {
+	return r.Registry + "/" + r.Repository
+}
+
+// MatchRegistry returns true if the image matches one of the glob patterns.
+// Patterns with a slash match the name of the image like
+// "docker.io/library/*", all others match the registry like "*.gcr.io".
+func (r ImageReference) MatchRegistry(patterns []string) bool {
+	for _, pattern := range patterns {
+		value := r.Registry
+		if strings.Contains(pattern, "/") {
+			value = r.Name()
+		}
+		if matched, _ := path.Match(pattern, value); matched {
+			return true
+		}
+	}
+	return false
+}
+
+var imageRules = []*imageRule{
+	{
+		id:          ImageReferenceRule,
+		description: "image of every container is a valid image reference",
+		invalid: func(image string, err error) string {
+			if image == "" {
+				return "image is missing"
+			}
+			return err.Error()
+		},
+	},
+	{
+		id:          ImageTagRule,
+		description: "image of every container has a tag other than latest or a digest",
+		check: func(obj *Object, image string, reference ImageReference) string {
+			if reference.Digest != "" {
+				return ""
+			}
+			if reference.Tag == "" {
+				return fmt.Sprintf("image %s has no tag", image)
+			}
+			if reference.Tag == "latest" {
+				return fmt.Sprintf("image %s uses the latest tag", image)
+			}
+			return ""
+		},
+	},
+	{
+		id:          ImageDigestRule,
+		description: "image of every container is pinned by digest",
+		check: func(obj *Object, image string, reference ImageReference) string {
+			if reference.Digest == "" {
+				return fmt.Sprintf("image %s is not pinned by digest", image)
+			}
+			return ""
+		},
+	},
+	{
+		id:          ImageRegistryRule,
+		description: "image of every container is pulled from a registry allowed by the configuration",
+		check: func(obj *Object, image string, reference ImageReference) string {
+			policy := obj.config.ImagePolicy()
+			if reference.MatchRegistry(policy.DeniedRegistries) {
+				return fmt.Sprintf("image %s is pulled from the denied registry %s", image, reference.Registry)
+			}
+			if len(policy.AllowedRegistries) > 0 && !reference.MatchRegistry(policy.AllowedRegistries) {
+				return fmt.Sprintf("image %s is not pulled from an allowed registry", image)
+			}
+			return ""
+		},
+	},
+}
+
+// imageRule checks the image of every container of a workload.
+type imageRule struct {
+	id          string
+	description string
+	// check returns the message of the finding for a valid image reference
+	// or an empty string if the image is valid.
+	check func(obj *Object, image string, reference ImageReference) string
+	// invalid returns the message of the finding for an invalid image
+	// reference, which is skipped if invalid is nil.
+	invalid func(image string, err error) string
+}
+
+func (i *imageRule) ID() string {
+	return i.id
+}
+
+func (i *imageRule) Description() string {
+	return i.description
+}
+
+func (i *imageRule) Severity() Severity {
+	return SeverityError
+}
+
+func (i *imageRule) Applies(obj *Object) bool {
+	return obj.Template != nil
+}
+
+func (i *imageRule) Check(obj *Object) []Finding {
+	var findings []Finding
+	for _, container := range obj.Containers() {
+		var message string
+		reference, err := ParseImageReference(container.Image)
+		if err != nil {
+			if i.invalid != nil {
+				message = i.invalid(container.Image, err)
+			}
+		} else if i.check != nil {
+			message = i.check(obj, container.Image, reference)
+		}
+		if message != "" {
+			findings = append(findings, Finding{
+				ContainerType: container.Type,
+				Container:     container.Name,
+				Field:         container.Path.Child("image").String(),
+				Message:       message,
+			})
+		}
+	}
+	return findings
+}
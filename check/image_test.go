@@ -0,0 +1,105 @@
+package check_test
+
+import (
+	"fmt"
+
+	"github.com/seibert-media/k8s-manifest-check/check"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const digest = "sha256:4bcdffd6ad1b82c8cdb6d4b7c4d1a2b0f9d4c5e3a1b2c3d4e5f60718293a4b5c"
+
+// podWithImages is a pod with placeholders for the images of two containers.
+const podWithImages = `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  initContainers:
+  - name: init
+    image: %q
+  containers:
+  - name: web
+    image: %q
+`
+
+var _ = Describe("ParseImageReference", func() {
+	references := []struct {
+		image     string
+		reference check.ImageReference
+	}{
+		{"nginx", check.ImageReference{Registry: "docker.io", Repository: "library/nginx"}},
+		{"nginx:1.25", check.ImageReference{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"}},
+		{"bitnami/redis:7.2", check.ImageReference{Registry: "docker.io", Repository: "bitnami/redis", Tag: "7.2"}},
+		{"localhost/app", check.ImageReference{Registry: "localhost", Repository: "app"}},
+		{"registry.example.com:5000/team/app:v1.0.0-rc.1", check.ImageReference{Registry: "registry.example.com:5000", Repository: "team/app", Tag: "v1.0.0-rc.1"}},
+		{"gcr.io/distroless/static@" + digest, check.ImageReference{Registry: "gcr.io", Repository: "distroless/static", Digest: digest}},
+		{"quay.io/prometheus/node-exporter:v1.6.1@" + digest, check.ImageReference{Registry: "quay.io", Repository: "prometheus/node-exporter", Tag: "v1.6.1", Digest: digest}},
+	}
+	for _, reference := range references {
+		reference := reference
+		It(fmt.Sprintf("parse %s", reference.image), func() {
+			Expect(check.ParseImageReference(reference.image)).To(Equal(reference.reference))
+		})
+	}
+	for _, image := range []string{"", "Nginx", "nginx:", "nginx::1", "nginx@sha256:abc", "$(IMAGE)", "registry.example.com/app:tag with space", "-nginx"} {
+		image := image
+		It(fmt.Sprintf("return error for %q", image), func() {
+			_, err := check.ParseImageReference(image)
+			Expect(err).NotTo(BeNil())
+		})
+	}
+})
+
+var _ = Describe("Images", func() {
+	var checker *check.Checker
+	findings := func(initImage, image string) []string {
+		return checkFindings(checker, "pod.yaml", fmt.Sprintf(podWithImages, initImage, image))
+	}
+	BeforeEach(func() {
+		checker = checkerWithRules(check.ImageReferenceRule, check.ImageTagRule, check.ImageDigestRule, check.ImageRegistryRule)
+		checker.Registry.Disable(check.ImageDigestRule)
+	})
+	It("require digests only if enabled", func() {
+		Expect(check.DefaultRegistry.Enabled(check.ImageTagRule)).To(BeTrue())
+		Expect(check.DefaultRegistry.Enabled(check.ImageDigestRule)).To(BeFalse())
+		Expect(findings("busybox:1.36", "nginx:1.25")).To(BeEmpty())
+		checker.Registry.Enable(check.ImageDigestRule)
+		Expect(findings("busybox:1.36", "nginx@"+digest)).To(Equal([]string{
+			"image busybox:1.36 is not pinned by digest in pod.yaml:8:5 (document 1, Pod web, initContainer init) [image-digest]",
+		}))
+	})
+	It("report missing and latest tags", func() {
+		Expect(findings("busybox", "nginx:latest")).To(Equal([]string{
+			"image busybox has no tag in pod.yaml:8:5 (document 1, Pod web, initContainer init) [image-latest-tag]",
+			"image nginx:latest uses the latest tag in pod.yaml:11:5 (document 1, Pod web, container web) [image-latest-tag]",
+		}))
+		Expect(findings("busybox@"+digest, "nginx:latest@"+digest)).To(BeEmpty())
+	})
+	It("report invalid image references only once", func() {
+		Expect(findings("", "Nginx:1.25")).To(Equal([]string{
+			"image is missing in pod.yaml:8:5 (document 1, Pod web, initContainer init) [image-reference]",
+			"image reference Nginx:1.25 is invalid in pod.yaml:11:5 (document 1, Pod web, container web) [image-reference]",
+		}))
+	})
+	It("enforce allowed and denied registries", func() {
+		config, err := check.ParseConfig([]byte(`images:
+  allowedRegistries: ["registry.example.com", "*.gcr.io", "docker.io/library/*"]
+  deniedRegistries: ["eu.gcr.io"]
+`))
+		Expect(err).To(BeNil())
+		Expect(config.Validate(checker.Registry)).To(BeNil())
+		checker.Config = config
+		Expect(findings("busybox:1.36", "registry.example.com/team/web:1.0")).To(BeEmpty())
+		Expect(findings("bitnami/redis:7.2", "eu.gcr.io/project/web:1.0")).To(Equal([]string{
+			"image bitnami/redis:7.2 is not pulled from an allowed registry in pod.yaml:8:5 (document 1, Pod web, initContainer init) [image-registry]",
+			"image eu.gcr.io/project/web:1.0 is pulled from the denied registry eu.gcr.io in pod.yaml:11:5 (document 1, Pod web, container web) [image-registry]",
+		}))
+	})
+	It("return error for invalid registry patterns", func() {
+		config, err := check.ParseConfig([]byte("images:\n  deniedRegistries: [\"[docker.io\"]\n"))
+		Expect(err).To(BeNil())
+		Expect(config.Validate(checker.Registry)).NotTo(BeNil())
+	})
+})
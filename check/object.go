@@ -0,0 +1,180 @@
+package check
+
+import (
+	"encoding/json"
+
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s_runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Object is a kubernetes object decoded from a single manifest document.
+type Object struct {
+	File string
+	// Document is the index of the document in the file starting with 1.
+	Document   int
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+	Labels     map[string]string
+	// Annotations of the object, which may suppress findings.
+	Annotations map[string]string
+	// Runtime is the object decoded into its typed API struct.
+	Runtime k8s_runtime.Object
+	// Template is the pod template of pod-bearing workloads and nil for all other objects.
+	Template *corev1.PodTemplateSpec
+
+	positions positions
+	config    *Config
+	schemas   *Schemas
+	// content is the YAML document of the object.
+	content []byte
+}
+
+// NewObject returns the object for the decoded kubernetes object.
+func NewObject(file string, document int, obj k8s_runtime.Object) *Object {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	result := &Object{
+		File:       file,
+		Document:   document,
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       gvk.Kind,
+		Runtime:    obj,
+	}
+	if accessor, ok := obj.(metav1.Object); ok {
+		result.Namespace = accessor.GetNamespace()
+		result.Name = accessor.GetName()
+		result.Labels = accessor.GetLabels()
+		result.Annotations = accessor.GetAnnotations()
+	}
+	if template, ok := PodTemplate(obj); ok {
+		result.Template = template
+	}
+	return result
+}
+
+// QualifiedName returns the name of the object prefixed with its namespace if set.
+func (o *Object) QualifiedName() string {
+	return qualifiedName(o.Namespace, o.Name)
+}
+
+// PodSpecPath returns the field path of the pod spec of the pod template.
+func (o *Object) PodSpecPath() *field.Path {
+	keys := o.podSpecKeys()
+	return field.NewPath(keys[0], keys[1:]...)
+}
+
+// podSpecKeys returns the keys of the pod spec in the document.
+func (o *Object) podSpecKeys() []string {
+	switch o.Kind {
+	case "Pod":
+		return []string{"spec"}
+	case "PodTemplate":
+		return []string{"template", "spec"}
+	case "CronJob":
+		return []string{"spec", "jobTemplate", "spec", "template", "spec"}
+	case "JobTemplate":
+		return []string{"template", "spec", "template", "spec"}
+	}
+	return []string{"spec", "template", "spec"}
+}
+
+// Containers returns all containers of the pod template with their field paths.
+func (o *Object) Containers() []Container {
+	if o.Template == nil {
+		return nil
+	}
+	containers := Containers(o.Template.Spec)
+	for i := range containers {
+		containers[i].Path = o.PodSpecPath().Child(containers[i].Type.Field()).Index(containers[i].Index)
+	}
+	return containers
+}
+
+// Threshold returns the configured threshold of the rule for the object or
+// the default value if it is not configured.
+func (o *Object) Threshold(rule, name string, defaultValue float64) float64 {
+	if value, ok := o.config.Rule(rule, o).Thresholds[name]; ok {
+		return value
+	}
+	return defaultValue
+}
+
+// Position returns the position of the field path in the file or of its
+// nearest parent if the field is missing. The empty path is the start of the object.
+func (o *Object) Position(path string) (Position, bool) {
+	return o.positions.lookup(path)
+}
+
+// raw returns the document decoded into generic JSON values.
+func (o *Object) raw() (interface{}, error) {
+	var result interface{}
+	if err := yaml.Unmarshal(o.content, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// decodePodSpec decodes the pod spec of the document into spec. It is used
+// to read fields missing in the vendored API types.
+func (o *Object) decodePodSpec(spec interface{}) bool {
+	return o.decode(spec, o.podSpecKeys()...)
+}
+
+// decode decodes the field of the document at the given keys into value.
+func (o *Object) decode(value interface{}, keys ...string) bool {
+	raw, err := o.raw()
+	if err != nil {
+		return false
+	}
+	for _, key := range keys {
+		values, ok := raw.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		raw = values[key]
+	}
+	content, err := json.Marshal(raw)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(content, value) == nil
+}
+
+// dataKeys returns the keys of data, binaryData and stringData of
+// ConfigMaps and Secrets read from the document. The keys of SealedSecrets
+// are those of the encrypted and the template data.
+func (o *Object) dataKeys() []string {
+	fields := [][]string{{"data"}, {"binaryData"}, {"stringData"}}
+	if isSealedSecret(o) {
+		fields = [][]string{{"spec", "encryptedData"}, {"spec", "template", "data"}}
+	}
+	var result []string
+	for _, keys := range fields {
+		values := make(map[string]interface{})
+		if o.decode(&values, keys...) {
+			result = append(result, sortedKeys(values)...)
+		}
+	}
+	return result
+}
+
+// complete sets the reference to the object and the position in the finding.
+func (o *Object) complete(finding Finding) Finding {
+	if finding.Line == 0 {
+		if position, ok := o.Position(finding.Field); ok {
+			finding.Line = position.Line
+			finding.Column = position.Column
+		}
+	}
+	finding.File = o.File
+	finding.Document = o.Document
+	finding.APIVersion = o.APIVersion
+	finding.Kind = o.Kind
+	finding.Namespace = o.Namespace
+	finding.Name = o.Name
+	return finding
+}
@@ -0,0 +1,504 @@
+package check
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// SchemaRule is the ID of the rule validating objects against OpenAPI schemas.
+const SchemaRule = "openapi-schema"
+
+func init() {
+	Register(&schemaRule{})
+}
+
+// schemaRule validates objects against the schema of their kind. Objects
+// without schema are not validated.
+type schemaRule struct{}
+
+func (s *schemaRule) ID() string {
+	return SchemaRule
+}
+
+func (s *schemaRule) Description() string {
+	return "every object is valid according to the OpenAPI schema of its kind"
+}
+
+func (s *schemaRule) Severity() Severity {
+	return SeverityError
+}
+
+func (s *schemaRule) Applies(obj *Object) bool {
+	return obj.schemas.Has(obj.APIVersion, obj.Kind)
+}
+
+func (s *schemaRule) Check(obj *Object) []Finding {
+	if !s.Applies(obj) {
+		return nil
+	}
+	raw, err := obj.raw()
+	if err != nil {
+		return nil
+	}
+	var findings []Finding
+	for _, schemaError := range obj.schemas.Validate(obj.APIVersion, obj.Kind, raw) {
+		findings = append(findings, Finding{Field: schemaError.Path.String(), Message: schemaError.Error()})
+	}
+	return findings
+}
+
+// Schema is an OpenAPI v2 or v3 schema object with the keywords used by Kubernetes.
+type Schema struct {
+	Ref                  string                   `json:"$ref,omitempty"`
+	Type                 string                   `json:"type,omitempty"`
+	Format               string                   `json:"format,omitempty"`
+	Pattern              string                   `json:"pattern,omitempty"`
+	Enum                 []interface{}            `json:"enum,omitempty"`
+	Required             []string                 `json:"required,omitempty"`
+	Properties           map[string]*Schema       `json:"properties,omitempty"`
+	AdditionalProperties *AdditionalProperties    `json:"additionalProperties,omitempty"`
+	Items                *Schema                  `json:"items,omitempty"`
+	AllOf                []*Schema                `json:"allOf,omitempty"`
+	AnyOf                []*Schema                `json:"anyOf,omitempty"`
+	OneOf                []*Schema                `json:"oneOf,omitempty"`
+	Minimum              *float64                 `json:"minimum,omitempty"`
+	Maximum              *float64                 `json:"maximum,omitempty"`
+	MinLength            *int                     `json:"minLength,omitempty"`
+	MaxLength            *int                     `json:"maxLength,omitempty"`
+	MinItems             *int                     `json:"minItems,omitempty"`
+	MaxItems             *int                     `json:"maxItems,omitempty"`
+	IntOrString          bool                     `json:"x-kubernetes-int-or-string,omitempty"`
+	PreserveUnknown      bool                     `json:"x-kubernetes-preserve-unknown-fields,omitempty"`
+	EmbeddedResource     bool                     `json:"x-kubernetes-embedded-resource,omitempty"`
+	GroupVersionKinds    []schemaGroupVersionKind `json:"x-kubernetes-group-version-kind,omitempty"`
+}
+
+// AdditionalProperties is either a boolean or a schema.
+type AdditionalProperties struct {
+	Allowed bool
+	Schema  *Schema
+}
+
+// UnmarshalJSON decodes a boolean or a schema.
+func (a *AdditionalProperties) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &a.Allowed); err == nil {
+		return nil
+	}
+	a.Allowed = true
+	return json.Unmarshal(data, &a.Schema)
+}
+
+type schemaGroupVersionKind struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// openAPIDocument is a Kubernetes OpenAPI v2 or v3 document.
+type openAPIDocument struct {
+	Definitions map[string]*Schema `json:"definitions"`
+	Components  struct {
+		Schemas map[string]*Schema `json:"schemas"`
+	} `json:"components"`
+}
+
+// schemaRoot is the schema of a kind and the definitions its references are resolved with.
+type schemaRoot struct {
+	schema      *Schema
+	definitions map[string]*Schema
+	// strict rejects fields not defined by the schema, like the API server
+	// prunes them for custom resources.
+	strict bool
+}
+
+// Schemas holds the schemas of all kinds by group, version and kind.
+type Schemas struct {
+	kinds map[schemaGroupVersionKind]*schemaRoot
+}
+
+// NewSchemas returns an empty set of schemas.
+func NewSchemas() *Schemas {
+	return &Schemas{kinds: make(map[schemaGroupVersionKind]*schemaRoot)}
+}
+
+// LoadSchemas reads the OpenAPI documents at the given paths. Directories
+// are searched recursively for JSON files.
+func LoadSchemas(paths ...string) (*Schemas, error) {
+	schemas := NewSchemas()
+	for _, path := range paths {
+		err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || (file != path && filepath.Ext(file) != ".json") {
+				return nil
+			}
+			content, err := ioutil.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			if err := schemas.AddOpenAPI(content); err != nil {
+				return fmt.Errorf("%v in %s", err, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("load schemas failed: %v", err)
+		}
+	}
+	return schemas, nil
+}
+
+// AddOpenAPI adds the schemas of all kinds defined by the OpenAPI v2 or v3 document.
+func (s *Schemas) AddOpenAPI(content []byte) error {
+	document := &openAPIDocument{}
+	if err := json.Unmarshal(content, document); err != nil {
+		return fmt.Errorf("parse openapi failed: %v", err)
+	}
+	definitions := make(map[string]*Schema)
+	for name, definition := range document.Definitions {
+		definitions["#/definitions/"+name] = definition
+	}
+	for name, definition := range document.Components.Schemas {
+		definitions["#/components/schemas/"+name] = definition
+	}
+	for _, definition := range definitions {
+		for _, gvk := range definition.GroupVersionKinds {
+			s.kinds[gvk] = &schemaRoot{schema: definition, definitions: definitions}
+		}
+	}
+	return nil
+}
+
+// Len returns the number of kinds with schema.
+func (s *Schemas) Len() int {
+	return len(s.kinds)
+}
+
+// Has returns true if the schemas contain the kind.
+func (s *Schemas) Has(apiVersion, kind string) bool {
+	if s == nil {
+		return false
+	}
+	_, ok := s.root(apiVersion, kind)
+	return ok
+}
+
+// SchemaError is a violation of a schema.
+type SchemaError struct {
+	Path    *field.Path
+	Message string
+}
+
+func (e SchemaError) Error() string {
+	if e.Path.String() == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s %s", e.Path.String(), e.Message)
+}
+
+// Validate validates the decoded JSON value of the object against the schema
+// of its kind. Kinds without schema are valid.
+func (s *Schemas) Validate(apiVersion, kind string, value interface{}) []SchemaError {
+	root, ok := s.root(apiVersion, kind)
+	if !ok {
+		return nil
+	}
+	v := &schemaValidator{definitions: root.definitions, strict: root.strict}
+	return v.validate(value, root.schema, nil)
+}
+
+// UnknownFields returns all fields of the decoded JSON value, which are not
+// defined by the schema of its kind. Fields of objects without defined
+// properties or with additional properties are not unknown. Kinds pruned
+// like custom resources are skipped, because their unknown fields are
+// reported by the validation already.
+func (s *Schemas) UnknownFields(apiVersion, kind string, value interface{}) []UnknownField {
+	root, ok := s.root(apiVersion, kind)
+	if !ok || root.strict {
+		return nil
+	}
+	v := &schemaValidator{definitions: root.definitions}
+	return v.unknownFields(value, root.schema, nil)
+}
+
+func (s *Schemas) root(apiVersion, kind string) (*schemaRoot, bool) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return nil, false
+	}
+	root, ok := s.kinds[schemaGroupVersionKind{Group: gv.Group, Version: gv.Version, Kind: kind}]
+	return root, ok
+}
+
+type schemaValidator struct {
+	definitions map[string]*Schema
+	strict      bool
+}
+
+// resolve follows the references of the schema. References to quantities are
+// resolved to int-or-string schemas, because quantities are written as numbers too.
+func (v *schemaValidator) resolve(s *Schema) *Schema {
+	for s != nil && s.Ref != "" {
+		if strings.HasSuffix(s.Ref, ".apimachinery.pkg.api.resource.Quantity") {
+			return &Schema{IntOrString: true}
+		}
+		s = v.definitions[s.Ref]
+	}
+	return s
+}
+
+// property returns the schema of the property of an object schema or nil if it is unknown.
+func (v *schemaValidator) property(s *Schema, name string) *Schema {
+	s = v.resolve(s)
+	if s == nil {
+		return nil
+	}
+	if property, ok := s.Properties[name]; ok {
+		return property
+	}
+	for _, sub := range s.AllOf {
+		if property := v.property(sub, name); property != nil {
+			return property
+		}
+	}
+	if s.AdditionalProperties != nil {
+		return s.AdditionalProperties.Schema
+	}
+	return nil
+}
+
+func (v *schemaValidator) validate(value interface{}, s *Schema, path *field.Path) []SchemaError {
+	s = v.resolve(s)
+	if s == nil || value == nil {
+		return nil
+	}
+	var result []SchemaError
+	for _, sub := range s.AllOf {
+		result = append(result, v.validate(value, sub, path)...)
+	}
+	for _, alternatives := range [][]*Schema{s.AnyOf, s.OneOf} {
+		if len(alternatives) > 0 && !s.IntOrString && !v.matchesAny(value, alternatives, path) {
+			result = append(result, SchemaError{Path: path, Message: "does not match any of the allowed schemas"})
+		}
+	}
+	if err := v.validateType(value, s, path); err != nil {
+		return append(result, *err)
+	}
+	if len(s.Enum) > 0 && !containsValue(s.Enum, value) {
+		var allowed []string
+		for _, e := range s.Enum {
+			allowed = append(allowed, fmt.Sprintf("%q", fmt.Sprint(e)))
+		}
+		result = append(result, SchemaError{Path: path, Message: fmt.Sprintf("must be one of %s", strings.Join(allowed, ", "))})
+	}
+	switch value := value.(type) {
+	case string:
+		result = append(result, v.validateString(value, s, path)...)
+	case float64:
+		result = append(result, v.validateNumber(value, s, path)...)
+	case map[string]interface{}:
+		result = append(result, v.validateObject(value, s, path)...)
+	case []interface{}:
+		if s.MinItems != nil && len(value) < *s.MinItems {
+			result = append(result, SchemaError{Path: path, Message: fmt.Sprintf("must have at least %d items", *s.MinItems)})
+		}
+		if s.MaxItems != nil && len(value) > *s.MaxItems {
+			result = append(result, SchemaError{Path: path, Message: fmt.Sprintf("must have at most %d items", *s.MaxItems)})
+		}
+		for i, item := range value {
+			result = append(result, v.validate(item, s.Items, path.Index(i))...)
+		}
+	}
+	return result
+}
+
+func (v *schemaValidator) matchesAny(value interface{}, alternatives []*Schema, path *field.Path) bool {
+	for _, alternative := range alternatives {
+		if len(v.validate(value, alternative, path)) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *schemaValidator) validateType(value interface{}, s *Schema, path *field.Path) *SchemaError {
+	if s.IntOrString || s.Format == "int-or-string" {
+		if _, ok := value.(string); ok {
+			return nil
+		}
+		if number, ok := value.(float64); ok && number == math.Trunc(number) {
+			return nil
+		}
+		return &SchemaError{Path: path, Message: "must be an integer or a string"}
+	}
+	valid := true
+	switch s.Type {
+	case "object":
+		_, valid = value.(map[string]interface{})
+	case "array":
+		_, valid = value.([]interface{})
+	case "string":
+		_, valid = value.(string)
+	case "boolean":
+		_, valid = value.(bool)
+	case "number":
+		_, valid = value.(float64)
+	case "integer":
+		number, ok := value.(float64)
+		valid = ok && number == math.Trunc(number)
+	}
+	if !valid {
+		return &SchemaError{Path: path, Message: fmt.Sprintf("must be of type %s", s.Type)}
+	}
+	return nil
+}
+
+func (v *schemaValidator) validateString(value string, s *Schema, path *field.Path) []SchemaError {
+	var result []SchemaError
+	if s.Pattern != "" {
+		if pattern, err := regexp.Compile(s.Pattern); err == nil && !pattern.MatchString(value) {
+			result = append(result, SchemaError{Path: path, Message: fmt.Sprintf("must match the pattern %s", s.Pattern)})
+		}
+	}
+	if s.MinLength != nil && len(value) < *s.MinLength {
+		result = append(result, SchemaError{Path: path, Message: fmt.Sprintf("must be at least %d characters long", *s.MinLength)})
+	}
+	if s.MaxLength != nil && len(value) > *s.MaxLength {
+		result = append(result, SchemaError{Path: path, Message: fmt.Sprintf("must be at most %d characters long", *s.MaxLength)})
+	}
+	switch s.Format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			result = append(result, SchemaError{Path: path, Message: "must be a RFC 3339 date-time"})
+		}
+	case "byte":
+		if _, err := base64.StdEncoding.DecodeString(value); err != nil {
+			result = append(result, SchemaError{Path: path, Message: "must be base64 encoded"})
+		}
+	}
+	return result
+}
+
+func (v *schemaValidator) validateNumber(value float64, s *Schema, path *field.Path) []SchemaError {
+	var result []SchemaError
+	if s.Minimum != nil && value < *s.Minimum {
+		result = append(result, SchemaError{Path: path, Message: fmt.Sprintf("must be greater than or equal to %v", *s.Minimum)})
+	}
+	if s.Maximum != nil && value > *s.Maximum {
+		result = append(result, SchemaError{Path: path, Message: fmt.Sprintf("must be less than or equal to %v", *s.Maximum)})
+	}
+	if s.Format == "int32" && (value < math.MinInt32 || value > math.MaxInt32) {
+		result = append(result, SchemaError{Path: path, Message: "must be a 32 bit integer"})
+	}
+	return result
+}
+
+func (v *schemaValidator) validateObject(values map[string]interface{}, s *Schema, path *field.Path) []SchemaError {
+	var result []SchemaError
+	for _, name := range s.Required {
+		if values[name] == nil {
+			result = append(result, SchemaError{Path: path.Child(name), Message: "is required"})
+		}
+	}
+	for _, key := range sortedKeys(values) {
+		property, ok := s.Properties[key]
+		if !ok && s.AdditionalProperties != nil {
+			property = s.AdditionalProperties.Schema
+		}
+		if property != nil {
+			result = append(result, v.validate(values[key], property, path.Child(key))...)
+			continue
+		}
+		// properties of allOf schemas are validated by them
+		if v.property(s, key) == nil && v.unknownForbidden(s) {
+			result = append(result, SchemaError{Path: path.Child(key), Message: "is not defined by the schema"})
+		}
+	}
+	return result
+}
+
+func (v *schemaValidator) unknownFields(value interface{}, s *Schema, path *field.Path) []UnknownField {
+	s = v.resolve(s)
+	if s == nil || s.PreserveUnknown || s.EmbeddedResource {
+		return nil
+	}
+	var result []UnknownField
+	switch value := value.(type) {
+	case map[string]interface{}:
+		names := v.propertyNames(s)
+		for _, key := range sortedKeys(value) {
+			property := v.property(s, key)
+			if property == nil && s.AdditionalProperties != nil {
+				property = s.AdditionalProperties.Schema
+			}
+			if property != nil {
+				result = append(result, v.unknownFields(value[key], property, path.Child(key))...)
+				continue
+			}
+			// additionalProperties false is reported by the validation
+			if len(names) > 0 && s.AdditionalProperties == nil {
+				result = append(result, UnknownField{Path: path.Child(key), Name: key, Suggestion: suggest(key, names)})
+			}
+		}
+	case []interface{}:
+		items := s.Items
+		for _, sub := range s.AllOf {
+			if sub = v.resolve(sub); items == nil && sub != nil {
+				items = sub.Items
+			}
+		}
+		for i, item := range value {
+			result = append(result, v.unknownFields(item, items, path.Index(i))...)
+		}
+	}
+	return result
+}
+
+// propertyNames returns the names of the properties of an object schema and its allOf schemas.
+func (v *schemaValidator) propertyNames(s *Schema) []string {
+	s = v.resolve(s)
+	if s == nil {
+		return nil
+	}
+	var names []string
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	for _, sub := range s.AllOf {
+		names = append(names, v.propertyNames(sub)...)
+	}
+	return names
+}
+
+// unknownForbidden returns true if the object schema does not allow properties it does not define.
+func (v *schemaValidator) unknownForbidden(s *Schema) bool {
+	if s.PreserveUnknown || s.EmbeddedResource {
+		return false
+	}
+	if s.AdditionalProperties != nil {
+		return !s.AdditionalProperties.Allowed
+	}
+	return v.strict
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,247 @@
+package check_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/seibert-media/k8s-manifest-check/check"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// swagger is an excerpt of the OpenAPI v2 document of Kubernetes.
+const swagger = `{
+  "swagger": "2.0",
+  "definitions": {
+    "io.k8s.api.apps.v1.Deployment": {
+      "properties": {
+        "apiVersion": {"type": "string"},
+        "kind": {"type": "string"},
+        "metadata": {"$ref": "#/definitions/io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"},
+        "spec": {"$ref": "#/definitions/io.k8s.api.apps.v1.DeploymentSpec"}
+      },
+      "x-kubernetes-group-version-kind": [{"group": "apps", "kind": "Deployment", "version": "v1"}]
+    },
+    "io.k8s.api.apps.v1.DeploymentSpec": {
+      "required": ["selector", "template"],
+      "properties": {
+        "replicas": {"type": "integer", "format": "int32", "minimum": 0},
+        "selector": {"type": "object"},
+        "template": {"$ref": "#/definitions/io.k8s.api.core.v1.PodTemplateSpec"}
+      }
+    },
+    "io.k8s.api.core.v1.PodTemplateSpec": {
+      "properties": {
+        "spec": {"$ref": "#/definitions/io.k8s.api.core.v1.PodSpec"}
+      }
+    },
+    "io.k8s.api.core.v1.PodSpec": {
+      "required": ["containers"],
+      "properties": {
+        "containers": {"type": "array", "items": {"$ref": "#/definitions/io.k8s.api.core.v1.Container"}}
+      }
+    },
+    "io.k8s.api.core.v1.Container": {
+      "required": ["name"],
+      "properties": {
+        "name": {"type": "string", "pattern": "^[a-z0-9]([-a-z0-9]*[a-z0-9])?$"},
+        "imagePullPolicy": {"type": "string", "enum": ["Always", "IfNotPresent", "Never"]},
+        "resources": {"$ref": "#/definitions/io.k8s.api.core.v1.ResourceRequirements"}
+      }
+    },
+    "io.k8s.api.core.v1.ResourceRequirements": {
+      "properties": {
+        "limits": {"type": "object", "additionalProperties": {"$ref": "#/definitions/io.k8s.apimachinery.pkg.api.resource.Quantity"}}
+      }
+    },
+    "io.k8s.apimachinery.pkg.api.resource.Quantity": {"type": "string"},
+    "io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta": {
+      "properties": {
+        "name": {"type": "string"},
+        "creationTimestamp": {"type": "string", "format": "date-time"}
+      }
+    }
+  }
+}`
+
+// openAPIV3 is an excerpt of the OpenAPI v3 document of the batch/v1 group.
+const openAPIV3 = `{
+  "openapi": "3.0.0",
+  "components": {
+    "schemas": {
+      "io.k8s.api.batch.v1.Job": {
+        "type": "object",
+        "properties": {
+          "spec": {"allOf": [{"$ref": "#/components/schemas/io.k8s.api.batch.v1.JobSpec"}]}
+        },
+        "x-kubernetes-group-version-kind": [{"group": "batch", "kind": "Job", "version": "v1"}]
+      },
+      "io.k8s.api.batch.v1.JobSpec": {
+        "type": "object",
+        "properties": {
+          "backoffLimit": {"type": "integer", "format": "int32", "minimum": 0},
+          "completionMode": {"type": "string", "enum": ["Indexed", "NonIndexed"]}
+        },
+        "additionalProperties": false
+      }
+    }
+  }
+}`
+
+var _ = Describe("Schemas", func() {
+	var checker *check.Checker
+	validate := func(content string) []string {
+		report := &check.Report{}
+		checker.Content(report, "manifest.yaml", []byte(content))
+		var findings []string
+		for _, finding := range report.Findings {
+			if finding.Rule == check.SchemaRule {
+				findings = append(findings, finding.String())
+			}
+		}
+		return findings
+	}
+	BeforeEach(func() {
+		checker = check.New()
+		checker.Schemas = check.NewSchemas()
+		Expect(checker.Schemas.AddOpenAPI([]byte(swagger))).To(BeNil())
+		Expect(checker.Schemas.AddOpenAPI([]byte(openAPIV3))).To(BeNil())
+		Expect(checker.Schemas.Len()).To(Equal(2))
+	})
+	It("report nothing for valid objects", func() {
+		Expect(validate(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  creationTimestamp: null
+spec:
+  replicas: 2
+  selector: {}
+  template:
+    spec:
+      containers:
+      - name: app
+        imagePullPolicy: Always
+        resources:
+          limits:
+            cpu: 1
+            memory: 100Mi
+`)).To(BeEmpty())
+	})
+	It("report violations of OpenAPI v2 schema", func() {
+		Expect(validate(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: -1
+  template:
+    spec:
+      containers:
+      - name: App
+        imagePullPolicy: Sometimes
+      - image: nginx
+`)).To(Equal([]string{
+			"spec.selector is required in manifest.yaml:5:1 (document 1, Deployment web) [openapi-schema]",
+			"spec.replicas must be greater than or equal to 0 in manifest.yaml:6:3 (document 1, Deployment web) [openapi-schema]",
+			`spec.template.spec.containers[0].imagePullPolicy must be one of "Always", "IfNotPresent", "Never" in manifest.yaml:11:9 (document 1, Deployment web) [openapi-schema]`,
+			"spec.template.spec.containers[0].name must match the pattern ^[a-z0-9]([-a-z0-9]*[a-z0-9])?$ in manifest.yaml:10:9 (document 1, Deployment web) [openapi-schema]",
+			"spec.template.spec.containers[1].name is required in manifest.yaml:12:7 (document 1, Deployment web) [openapi-schema]",
+		}))
+	})
+	It("report violations of OpenAPI v3 schema", func() {
+		Expect(validate(`apiVersion: batch/v1
+kind: Job
+metadata:
+  name: nightly
+spec:
+  backoffLimit: -1
+  completionMode: Sequential
+  parallelism: 2
+`)).To(Equal([]string{
+			"spec.backoffLimit must be greater than or equal to 0 in manifest.yaml:6:3 (document 1, Job nightly) [openapi-schema]",
+			`spec.completionMode must be one of "Indexed", "NonIndexed" in manifest.yaml:7:3 (document 1, Job nightly) [openapi-schema]`,
+			"spec.parallelism is not defined by the schema in manifest.yaml:8:3 (document 1, Job nightly) [openapi-schema]",
+		}))
+	})
+	It("validate kinds missing in the vendored api", func() {
+		Expect(checker.Schemas.AddOpenAPI([]byte(`{
+  "swagger": "2.0",
+  "definitions": {
+    "io.k8s.api.flowcontrol.v1.FlowSchema": {
+      "required": ["spec"],
+      "properties": {
+        "metadata": {"type": "object"},
+        "spec": {"type": "object", "properties": {"matchingPrecedence": {"type": "integer", "minimum": 1}}}
+      },
+      "x-kubernetes-group-version-kind": [{"group": "flowcontrol.apiserver.k8s.io", "kind": "FlowSchema", "version": "v1"}]
+    },
+    "io.k8s.api.batch.v1.CronJob": {
+      "properties": {
+        "metadata": {"type": "object"},
+        "spec": {"type": "object", "required": ["schedule"]}
+      },
+      "x-kubernetes-group-version-kind": [{"group": "batch", "kind": "CronJob", "version": "v1"}]
+    }
+  }
+}`))).To(BeNil())
+		report := &check.Report{}
+		checker.Content(report, "manifest.yaml", []byte(`apiVersion: flowcontrol.apiserver.k8s.io/v1
+kind: FlowSchema
+metadata:
+  name: service-accounts
+spec:
+  matchingPrecedence: 0
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: nightly
+spec:
+  jobTemplate: {}
+`))
+		var findings []string
+		for _, finding := range report.Findings {
+			findings = append(findings, finding.String())
+		}
+		Expect(findings).To(Equal([]string{
+			"spec.matchingPrecedence must be greater than or equal to 1 in manifest.yaml:6:3 (document 1, FlowSchema service-accounts) [openapi-schema]",
+			"spec.schedule is required in manifest.yaml:12:1 (document 2, CronJob nightly) [openapi-schema]",
+		}))
+	})
+	It("skip kinds without schema", func() {
+		Expect(validate(validPod)).To(BeEmpty())
+	})
+	It("load documents of directories", func() {
+		dir, err := ioutil.TempDir("", "schemas")
+		Expect(err).To(BeNil())
+		defer os.RemoveAll(dir)
+		Expect(ioutil.WriteFile(filepath.Join(dir, "swagger.json"), []byte(swagger), 0644)).To(BeNil())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("# schemas"), 0644)).To(BeNil())
+		schemas, err := check.LoadSchemas(dir)
+		Expect(err).To(BeNil())
+		Expect(schemas.Len()).To(Equal(1))
+	})
+	It("validate with the bundled documents", func() {
+		for _, version := range []string{"v1.21", "v1.23", "v1.26"} {
+			var err error
+			checker.Schemas, err = check.LoadSchemas(filepath.Join("..", "openapi", version))
+			Expect(err).To(BeNil())
+			Expect(validate(validPod)).To(BeEmpty(), version)
+			Expect(validate(`apiVersion: batch/v1
+kind: Job
+metadata:
+  name: nightly
+spec:
+  template:
+    spec:
+      restartPolicy: Never
+      containers:
+      - image: busybox:1.36
+`)).To(Equal([]string{
+				"spec.template.spec.containers[0].name is required in manifest.yaml:10:7 (document 1, Job nightly) [openapi-schema]",
+			}), version)
+		}
+	})
+})
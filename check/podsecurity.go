@@ -0,0 +1,346 @@
+package check
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Rule IDs of the Pod Security Standards levels.
+const (
+	PodSecurityBaselineRule   = "pod-security-baseline"
+	PodSecurityRestrictedRule = "pod-security-restricted"
+)
+
+func init() {
+	Register(&podSecurityRule{id: PodSecurityBaselineRule, description: "pod templates meet the baseline level of the Pod Security Standards"})
+	Register(&podSecurityRule{id: PodSecurityRestrictedRule, restricted: true, description: "pod templates meet the restricted level of the Pod Security Standards, which includes baseline"})
+	DefaultRegistry.Disable(PodSecurityBaselineRule)
+	DefaultRegistry.Disable(PodSecurityRestrictedRule)
+}
+
+// EphemeralContainerType is only used by the pod security rules, which read
+// ephemeral containers from the document.
+const EphemeralContainerType ContainerType = "ephemeralContainer"
+
+// baselineCapabilities may be added to containers by the baseline level.
+var baselineCapabilities = []string{"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL", "MKNOD", "NET_BIND_SERVICE", "SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT"}
+
+// restrictedCapabilities may be added to containers by the restricted level.
+var restrictedCapabilities = []string{"NET_BIND_SERVICE"}
+
+// safeSysctls are the namespaced sysctls allowed by the baseline level.
+var safeSysctls = []string{
+	"kernel.shm_rmid_forced",
+	"net.ipv4.ip_local_port_range",
+	"net.ipv4.ip_local_reserved_ports",
+	"net.ipv4.ip_unprivileged_port_start",
+	"net.ipv4.ping_group_range",
+	"net.ipv4.tcp_fin_timeout",
+	"net.ipv4.tcp_keepalive_intvl",
+	"net.ipv4.tcp_keepalive_probes",
+	"net.ipv4.tcp_keepalive_time",
+	"net.ipv4.tcp_syncookies",
+}
+
+// seLinuxTypes are the SELinux types allowed by the baseline level.
+var seLinuxTypes = []string{"", "container_t", "container_init_t", "container_kvm_t"}
+
+// restrictedVolumeTypes are the volume sources allowed by the restricted level.
+var restrictedVolumeTypes = []string{"configMap", "csi", "downwardAPI", "emptyDir", "ephemeral", "persistentVolumeClaim", "projected", "secret"}
+
+// podSecuritySpec is the part of a pod spec evaluated by the Pod Security
+// Standards. It is decoded from the document because the vendored API types
+// lack fields like seccompProfile, sysctls and procMount.
+type podSecuritySpec struct {
+	HostNetwork         bool                     `json:"hostNetwork"`
+	HostPID             bool                     `json:"hostPID"`
+	HostIPC             bool                     `json:"hostIPC"`
+	SecurityContext     *podSecurityContext      `json:"securityContext"`
+	Volumes             []map[string]interface{} `json:"volumes"`
+	InitContainers      []podSecurityContainer   `json:"initContainers"`
+	Containers          []podSecurityContainer   `json:"containers"`
+	EphemeralContainers []podSecurityContainer   `json:"ephemeralContainers"`
+}
+
+type podSecurityContext struct {
+	SELinuxOptions *seLinuxOptions     `json:"seLinuxOptions"`
+	RunAsUser      *int64              `json:"runAsUser"`
+	RunAsNonRoot   *bool               `json:"runAsNonRoot"`
+	SeccompProfile *seccompProfile     `json:"seccompProfile"`
+	WindowsOptions *windowsOptions     `json:"windowsOptions"`
+	Sysctls        []podSecuritySysctl `json:"sysctls"`
+}
+
+type podSecurityContainer struct {
+	Name  string `json:"name"`
+	Ports []struct {
+		HostPort int32 `json:"hostPort"`
+	} `json:"ports"`
+	SecurityContext *containerSecurityContext `json:"securityContext"`
+}
+
+type containerSecurityContext struct {
+	Privileged               *bool `json:"privileged"`
+	AllowPrivilegeEscalation *bool `json:"allowPrivilegeEscalation"`
+	Capabilities             *struct {
+		Add  []string `json:"add"`
+		Drop []string `json:"drop"`
+	} `json:"capabilities"`
+	ProcMount      *string         `json:"procMount"`
+	SELinuxOptions *seLinuxOptions `json:"seLinuxOptions"`
+	RunAsUser      *int64          `json:"runAsUser"`
+	RunAsNonRoot   *bool           `json:"runAsNonRoot"`
+	SeccompProfile *seccompProfile `json:"seccompProfile"`
+	WindowsOptions *windowsOptions `json:"windowsOptions"`
+}
+
+type seLinuxOptions struct {
+	User string `json:"user"`
+	Role string `json:"role"`
+	Type string `json:"type"`
+}
+
+type seccompProfile struct {
+	Type string `json:"type"`
+}
+
+type windowsOptions struct {
+	HostProcess *bool `json:"hostProcess"`
+}
+
+type podSecuritySysctl struct {
+	Name string `json:"name"`
+}
+
+// podSecurityRule evaluates the pod template of workloads against a level of
+// the Pod Security Standards. The restricted level reports violations of the
+// baseline level too.
+type podSecurityRule struct {
+	id          string
+	description string
+	restricted  bool
+}
+
+func (p *podSecurityRule) ID() string {
+	return p.id
+}
+
+func (p *podSecurityRule) Description() string {
+	return p.description
+}
+
+func (p *podSecurityRule) Severity() Severity {
+	return SeverityError
+}
+
+func (p *podSecurityRule) Applies(obj *Object) bool {
+	return obj.Template != nil
+}
+
+func (p *podSecurityRule) Check(obj *Object) []Finding {
+	if !p.Applies(obj) {
+		return nil
+	}
+	spec := &podSecuritySpec{}
+	if !obj.decodePodSpec(spec) {
+		return nil
+	}
+	e := &podSecurityEvaluation{obj: obj, spec: spec, path: obj.PodSpecPath(), restricted: p.restricted}
+	e.pod()
+	e.annotations()
+	e.volumes()
+	for _, list := range []struct {
+		containerType ContainerType
+		containers    []podSecurityContainer
+	}{
+		{InitContainerType, spec.InitContainers},
+		{RegularContainerType, spec.Containers},
+		{EphemeralContainerType, spec.EphemeralContainers},
+	} {
+		for i, container := range list.containers {
+			e.container(list.containerType, container, e.path.Child(list.containerType.Field()).Index(i))
+		}
+	}
+	return e.findings
+}
+
+type podSecurityEvaluation struct {
+	obj        *Object
+	spec       *podSecuritySpec
+	path       *field.Path
+	restricted bool
+	findings   []Finding
+}
+
+func (e *podSecurityEvaluation) add(path *field.Path, container *Container, format string, args ...interface{}) {
+	finding := Finding{Field: path.String(), Message: fmt.Sprintf(format, args...)}
+	if container != nil {
+		finding.ContainerType = container.Type
+		finding.Container = container.Name
+	}
+	e.findings = append(e.findings, finding)
+}
+
+func (e *podSecurityEvaluation) pod() {
+	if e.spec.HostNetwork {
+		e.add(e.path.Child("hostNetwork"), nil, "host network must not be used")
+	}
+	if e.spec.HostPID {
+		e.add(e.path.Child("hostPID"), nil, "host PID namespace must not be shared")
+	}
+	if e.spec.HostIPC {
+		e.add(e.path.Child("hostIPC"), nil, "host IPC namespace must not be shared")
+	}
+	context := e.spec.SecurityContext
+	if context == nil {
+		return
+	}
+	path := e.path.Child("securityContext")
+	e.seLinux(context.SELinuxOptions, path.Child("seLinuxOptions"), nil)
+	e.windows(context.WindowsOptions, path.Child("windowsOptions"), nil)
+	if context.SeccompProfile != nil && context.SeccompProfile.Type == "Unconfined" {
+		e.add(path.Child("seccompProfile", "type"), nil, "seccomp profile Unconfined is not allowed")
+	}
+	for i, sysctl := range context.Sysctls {
+		if !contains(safeSysctls, sysctl.Name) {
+			e.add(path.Child("sysctls").Index(i).Child("name"), nil, "sysctl %s is not allowed", sysctl.Name)
+		}
+	}
+	if e.restricted && context.RunAsUser != nil && *context.RunAsUser == 0 {
+		e.add(path.Child("runAsUser"), nil, "runAsUser must not be 0")
+	}
+}
+
+// annotations checks the AppArmor and seccomp annotations of the pod template.
+func (e *podSecurityEvaluation) annotations() {
+	keys := e.obj.podSpecKeys()
+	keys = append(keys[:len(keys)-1:len(keys)-1], "metadata", "annotations")
+	path := field.NewPath(keys[0], keys[1:]...)
+	annotations := e.obj.Template.Annotations
+	var names []string
+	for name := range annotations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := annotations[name]
+		switch {
+		case strings.HasPrefix(name, "container.apparmor.security.beta.kubernetes.io/"):
+			if value != "runtime/default" && !strings.HasPrefix(value, "localhost/") {
+				e.add(path.Key(name), nil, "AppArmor profile %s is not allowed", value)
+			}
+		case name == "seccomp.security.alpha.kubernetes.io/pod" || strings.HasPrefix(name, "container.seccomp.security.alpha.kubernetes.io/"):
+			if value == "unconfined" {
+				e.add(path.Key(name), nil, "seccomp profile unconfined is not allowed")
+			}
+		}
+	}
+}
+
+func (e *podSecurityEvaluation) volumes() {
+	for i, volume := range e.spec.Volumes {
+		for _, source := range sortedKeys(volume) {
+			if source == "name" {
+				continue
+			}
+			path := e.path.Child("volumes").Index(i).Child(source)
+			if source == "hostPath" {
+				e.add(path, nil, "hostPath volume %v is not allowed", volume["name"])
+			} else if e.restricted && !contains(restrictedVolumeTypes, source) {
+				e.add(path, nil, "%s volume %v is not allowed", source, volume["name"])
+			}
+		}
+	}
+}
+
+func (e *podSecurityEvaluation) container(containerType ContainerType, c podSecurityContainer, path *field.Path) {
+	container := &Container{Type: containerType}
+	container.Name = c.Name
+	for i, port := range c.Ports {
+		if port.HostPort != 0 {
+			e.add(path.Child("ports").Index(i).Child("hostPort"), container, "host port %d is not allowed", port.HostPort)
+		}
+	}
+	context := c.SecurityContext
+	if context == nil {
+		context = &containerSecurityContext{}
+	}
+	path = path.Child("securityContext")
+	if context.Privileged != nil && *context.Privileged {
+		e.add(path.Child("privileged"), container, "privileged containers are not allowed")
+	}
+	if context.ProcMount != nil && *context.ProcMount != "Default" {
+		e.add(path.Child("procMount"), container, "proc mount type %s is not allowed", *context.ProcMount)
+	}
+	e.seLinux(context.SELinuxOptions, path.Child("seLinuxOptions"), container)
+	e.windows(context.WindowsOptions, path.Child("windowsOptions"), container)
+	if context.SeccompProfile != nil && context.SeccompProfile.Type == "Unconfined" {
+		e.add(path.Child("seccompProfile", "type"), container, "seccomp profile Unconfined is not allowed")
+	}
+	allowed := baselineCapabilities
+	if e.restricted {
+		allowed = restrictedCapabilities
+	}
+	if context.Capabilities != nil {
+		for i, capability := range context.Capabilities.Add {
+			if !contains(allowed, capability) {
+				e.add(path.Child("capabilities", "add").Index(i), container, "capability %s must not be added", capability)
+			}
+		}
+	}
+	if !e.restricted {
+		return
+	}
+	if context.AllowPrivilegeEscalation == nil || *context.AllowPrivilegeEscalation {
+		e.add(path.Child("allowPrivilegeEscalation"), container, "allowPrivilegeEscalation must be false")
+	}
+	if context.Capabilities == nil || !contains(context.Capabilities.Drop, "ALL") {
+		e.add(path.Child("capabilities", "drop"), container, "capabilities must drop ALL")
+	}
+	pod := e.spec.SecurityContext
+	if pod == nil {
+		pod = &podSecurityContext{}
+	}
+	runAsNonRoot := context.RunAsNonRoot
+	if runAsNonRoot == nil {
+		runAsNonRoot = pod.RunAsNonRoot
+	}
+	if runAsNonRoot == nil || !*runAsNonRoot {
+		e.add(path.Child("runAsNonRoot"), container, "runAsNonRoot must be true")
+	}
+	if context.RunAsUser != nil && *context.RunAsUser == 0 {
+		e.add(path.Child("runAsUser"), container, "runAsUser must not be 0")
+	}
+	seccomp := context.SeccompProfile
+	if seccomp == nil {
+		seccomp = pod.SeccompProfile
+	}
+	// Unconfined is already reported by the baseline checks
+	if seccomp == nil || (seccomp.Type != "RuntimeDefault" && seccomp.Type != "Localhost" && seccomp.Type != "Unconfined") {
+		e.add(path.Child("seccompProfile", "type"), container, "seccomp profile must be RuntimeDefault or Localhost")
+	}
+}
+
+func (e *podSecurityEvaluation) seLinux(options *seLinuxOptions, path *field.Path, container *Container) {
+	if options == nil {
+		return
+	}
+	if !contains(seLinuxTypes, options.Type) {
+		e.add(path.Child("type"), container, "SELinux type %s is not allowed", options.Type)
+	}
+	if options.User != "" {
+		e.add(path.Child("user"), container, "SELinux user must not be set")
+	}
+	if options.Role != "" {
+		e.add(path.Child("role"), container, "SELinux role must not be set")
+	}
+}
+
+func (e *podSecurityEvaluation) windows(options *windowsOptions, path *field.Path, container *Container) {
+	if options != nil && options.HostProcess != nil && *options.HostProcess {
+		e.add(path.Child("hostProcess"), container, "Windows host process containers are not allowed")
+	}
+}
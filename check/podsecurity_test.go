@@ -0,0 +1,165 @@
+package check_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/seibert-media/k8s-manifest-check/check"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const privilegedDaemonSet = `apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: agent
+  namespace: monitoring
+spec:
+  template:
+    metadata:
+      annotations:
+        container.apparmor.security.beta.kubernetes.io/agent: unconfined
+    spec:
+      hostNetwork: true
+      hostPID: true
+      securityContext:
+        sysctls:
+        - name: kernel.msgmax
+          value: "65536"
+      volumes:
+      - name: proc
+        hostPath:
+          path: /proc
+      - name: config
+        configMap:
+          name: agent
+      containers:
+      - name: agent
+        image: agent:1.0
+        ports:
+        - containerPort: 9100
+          hostPort: 9100
+        securityContext:
+          privileged: true
+          capabilities:
+            add: ["NET_BIND_SERVICE", "SYS_ADMIN"]
+          seccompProfile:
+            type: Unconfined
+`
+
+const restrictedPod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+  namespace: payments
+spec:
+  securityContext:
+    runAsNonRoot: true
+    seccompProfile:
+      type: RuntimeDefault
+  volumes:
+  - name: cache
+    emptyDir: {}
+  containers:
+  - name: web
+    image: web:1.0
+    securityContext:
+      allowPrivilegeEscalation: false
+      capabilities:
+        drop: ["ALL"]
+        add: ["NET_BIND_SERVICE"]
+`
+
+const defaultPod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+  namespace: %s
+spec:
+  volumes:
+  - name: data
+    nfs:
+      server: nfs.example.com
+      path: /data
+  initContainers:
+  - name: init
+    image: busybox
+    securityContext:
+      runAsUser: 0
+  containers:
+  - name: web
+    image: web:1.0
+    securityContext:
+      runAsNonRoot: false
+`
+
+var _ = Describe("PodSecurity", func() {
+	var checker *check.Checker
+	findings := func(content string) []string {
+		return checkFindings(checker, "manifest.yaml", content)
+	}
+	BeforeEach(func() {
+		checker = checkerWithRules(check.PodSecurityBaselineRule, check.PodSecurityRestrictedRule)
+	})
+	It("be disabled by default", func() {
+		Expect(check.DefaultRegistry.Enabled(check.PodSecurityBaselineRule)).To(BeFalse())
+		Expect(check.DefaultRegistry.Enabled(check.PodSecurityRestrictedRule)).To(BeFalse())
+	})
+	It("report violations of the baseline level", func() {
+		checker.Registry.Disable(check.PodSecurityRestrictedRule)
+		Expect(findings(privilegedDaemonSet)).To(Equal([]string{
+			"host network must not be used in manifest.yaml:12:7 (document 1, DaemonSet monitoring/agent) [pod-security-baseline]",
+			"host PID namespace must not be shared in manifest.yaml:13:7 (document 1, DaemonSet monitoring/agent) [pod-security-baseline]",
+			"sysctl kernel.msgmax is not allowed in manifest.yaml:16:11 (document 1, DaemonSet monitoring/agent) [pod-security-baseline]",
+			"AppArmor profile unconfined is not allowed in manifest.yaml:9:7 (document 1, DaemonSet monitoring/agent) [pod-security-baseline]",
+			"hostPath volume proc is not allowed in manifest.yaml:20:9 (document 1, DaemonSet monitoring/agent) [pod-security-baseline]",
+			"host port 9100 is not allowed in manifest.yaml:30:11 (document 1, DaemonSet monitoring/agent, container agent) [pod-security-baseline]",
+			"privileged containers are not allowed in manifest.yaml:32:11 (document 1, DaemonSet monitoring/agent, container agent) [pod-security-baseline]",
+			"seccomp profile Unconfined is not allowed in manifest.yaml:36:13 (document 1, DaemonSet monitoring/agent, container agent) [pod-security-baseline]",
+			"capability SYS_ADMIN must not be added in manifest.yaml:34:39 (document 1, DaemonSet monitoring/agent, container agent) [pod-security-baseline]",
+		}))
+	})
+	It("report nothing for restricted pods", func() {
+		Expect(findings(restrictedPod)).To(BeEmpty())
+	})
+	It("report violations of the restricted level", func() {
+		checker.Registry.Disable(check.PodSecurityBaselineRule)
+		Expect(findings(fmt.Sprintf(defaultPod, "payments"))).To(Equal([]string{
+			"nfs volume data is not allowed in manifest.yaml:9:5 (document 1, Pod payments/web) [pod-security-restricted]",
+			"allowPrivilegeEscalation must be false in manifest.yaml:15:5 (document 1, Pod payments/web, initContainer init) [pod-security-restricted]",
+			"capabilities must drop ALL in manifest.yaml:15:5 (document 1, Pod payments/web, initContainer init) [pod-security-restricted]",
+			"runAsNonRoot must be true in manifest.yaml:15:5 (document 1, Pod payments/web, initContainer init) [pod-security-restricted]",
+			"runAsUser must not be 0 in manifest.yaml:16:7 (document 1, Pod payments/web, initContainer init) [pod-security-restricted]",
+			"seccomp profile must be RuntimeDefault or Localhost in manifest.yaml:15:5 (document 1, Pod payments/web, initContainer init) [pod-security-restricted]",
+			"allowPrivilegeEscalation must be false in manifest.yaml:20:5 (document 1, Pod payments/web, container web) [pod-security-restricted]",
+			"capabilities must drop ALL in manifest.yaml:20:5 (document 1, Pod payments/web, container web) [pod-security-restricted]",
+			"runAsNonRoot must be true in manifest.yaml:21:7 (document 1, Pod payments/web, container web) [pod-security-restricted]",
+			"seccomp profile must be RuntimeDefault or Localhost in manifest.yaml:20:5 (document 1, Pod payments/web, container web) [pod-security-restricted]",
+		}))
+	})
+	It("select the level per namespace", func() {
+		config, err := check.ParseConfig([]byte(`rules:
+  pod-security-baseline:
+    enabled: true
+overrides:
+- namespaces: ["payments", "payments-*"]
+  rules:
+    pod-security-baseline:
+      enabled: false
+    pod-security-restricted:
+      enabled: true
+- namespaces: ["kube-system"]
+  rules:
+    pod-security-baseline:
+      enabled: false
+`))
+		Expect(err).To(BeNil())
+		checker.Registry.Disable(check.PodSecurityRestrictedRule)
+		Expect(config.Apply(checker.Registry)).To(BeNil())
+		checker.Config = config
+		Expect(findings(fmt.Sprintf(defaultPod, "default"))).To(BeEmpty())
+		Expect(findings(fmt.Sprintf(defaultPod, "payments-eu"))).To(HaveLen(10))
+		Expect(findings(privilegedDaemonSet)).To(HaveLen(9))
+		Expect(findings(strings.Replace(privilegedDaemonSet, "monitoring", "kube-system", 1))).To(BeEmpty())
+	})
+})
@@ -0,0 +1,88 @@
+package check_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/seibert-media/k8s-manifest-check/check"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// writeManifests writes count files of invalid pods to a new directory.
+func writeManifests(count int) (string, error) {
+	dir, err := ioutil.TempDir("", "manifests")
+	if err != nil {
+		return "", err
+	}
+	for i := 0; i < count; i++ {
+		file := filepath.Join(dir, fmt.Sprintf("pod-%03d.yaml", i))
+		content := fmt.Sprintf("%s---\n%s", validPod, `apiVersion: v1
+kind: Pod
+metadata:
+  name: invalid
+spec:
+  containers:
+  - name: hello
+    image: "ubuntu:14.04"
+`)
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			os.RemoveAll(dir)
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+var _ = Describe("Paths", func() {
+	var dir string
+	BeforeEach(func() {
+		var err error
+		dir, err = writeManifests(20)
+		Expect(err).To(BeNil())
+	})
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+	It("report the same findings ordered by path with any number of jobs", func() {
+		sequential := &check.Report{}
+		checker := check.New()
+		checker.Jobs = 1
+		checker.Paths(sequential, dir, filepath.Join(dir, "pod-005.yaml"), filepath.Join(dir, "missing.yaml"))
+		Expect(sequential.Files).To(HaveLen(22))
+		Expect(sequential.Files[0]).To(Equal(filepath.Join(dir, "missing.yaml")))
+		Expect(sequential.Files[1]).To(Equal(filepath.Join(dir, "pod-000.yaml")))
+
+		concurrent := &check.Report{}
+		checker.Jobs = 8
+		checker.Paths(concurrent, dir, filepath.Join(dir, "pod-005.yaml"), filepath.Join(dir, "missing.yaml"))
+		Expect(concurrent.Files).To(Equal(sequential.Files))
+		Expect(concurrent.Findings).To(Equal(sequential.Findings))
+	})
+})
+
+func benchmarkPaths(b *testing.B, jobs int) {
+	dir, err := writeManifests(500)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	checker := check.New()
+	checker.Jobs = jobs
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		checker.Paths(&check.Report{}, dir)
+	}
+}
+
+func BenchmarkPathsSequential(b *testing.B) {
+	benchmarkPaths(b, 1)
+}
+
+func BenchmarkPathsConcurrent(b *testing.B) {
+	benchmarkPaths(b, runtime.NumCPU())
+}
@@ -0,0 +1,458 @@
+package check
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Position in a file, line and column start with 1.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// positionNode is the root, a mapping key or a sequence item of a YAML
+// document with its position in the file. Children are keyed by the key or by
+// the index in brackets like "[0]".
+type positionNode struct {
+	position Position
+	// scalar is true for nodes with a scalar value, which have no children.
+	scalar   bool
+	children map[string]*positionNode
+}
+
+// child adds the child with the given segment and position.
+func (n *positionNode) child(segment string, position Position) *positionNode {
+	if n.children == nil {
+		n.children = make(map[string]*positionNode)
+	}
+	result := &positionNode{position: position}
+	n.children[segment] = result
+	return result
+}
+
+// resolve returns the node of the path like ".spec.containers[0]" or its
+// nearest parent if the field is missing, together with the length of the
+// resolved part of the path. Keys may contain dots, so every key matching the
+// path is tried and the node resolving the longest part wins.
+func (n *positionNode) resolve(path string) (*positionNode, int) {
+	result, resolved := n, 0
+	var segments []string
+	for segment := range n.children {
+		segments = append(segments, segment)
+	}
+	// longer keys win if both resolve the same part like "a.b" and "a" with child "b"
+	sort.Slice(segments, func(i, j int) bool {
+		return len(segments[i]) > len(segments[j])
+	})
+	for _, segment := range segments {
+		prefix := segment
+		if !strings.HasPrefix(segment, "[") {
+			prefix = "." + segment
+		}
+		rest := strings.TrimPrefix(path, prefix)
+		if len(rest) == len(path) || (rest != "" && rest[0] != '.' && rest[0] != '[') || (rest != "" && n.children[segment].scalar) {
+			continue
+		}
+		node, length := n.children[segment].resolve(rest)
+		if length += len(prefix); length > resolved {
+			result, resolved = node, length
+		}
+	}
+	return result, resolved
+}
+
+// positions indexes the positions of all keys and sequence items of a YAML document.
+type positions struct {
+	root *positionNode
+}
+
+// lookup returns the position of the field path like "spec.containers[0].resources"
+// or of its nearest parent if the field is missing. The empty path is the
+// position of the document.
+func (p positions) lookup(path string) (Position, bool) {
+	if p.root == nil {
+		return Position{}, false
+	}
+	if path != "" && !strings.HasPrefix(path, "[") {
+		path = "." + path
+	}
+	node, _ := p.root.resolve(path)
+	return node.position, true
+}
+
+// positionFrame is a mapping or sequence of the block currently parsed.
+type positionFrame struct {
+	indent   int
+	node     *positionNode
+	sequence bool
+	next     int
+}
+
+// positionParser indexes block style YAML line by line. Flow collections,
+// which include JSON documents, are indexed by a flowScanner. Multi-line
+// scalars and aliases are indexed by the position of their key only.
+type positionParser struct {
+	lines     []string
+	firstLine int
+	root      *positionNode
+	stack     []*positionFrame
+	// pending is the node of the last key or item without value on its line,
+	// its children follow on the next lines.
+	pending       *positionNode
+	pendingIndent int
+	pendingStrict bool
+	// skipIndent is set for multi-line values, lines indented deeper are skipped.
+	skipIndent int
+	// flowDocument is set if the document is a flow collection like JSON,
+	// which is indexed entirely by its first line.
+	flowDocument bool
+}
+
+// parsePositions indexes the document, which starts at firstLine in its file.
+func parsePositions(document []byte, firstLine int) positions {
+	parser := &positionParser{firstLine: firstLine, skipIndent: -1}
+	for _, line := range bytes.Split(document, []byte("\n")) {
+		parser.lines = append(parser.lines, strings.TrimRight(string(line), "\r"))
+	}
+	for i, line := range parser.lines {
+		if parser.flowDocument {
+			break
+		}
+		parser.line(i, line)
+	}
+	return positions{root: parser.root}
+}
+
+func (p *positionParser) line(index int, line string) {
+	number := p.firstLine + index
+	content := strings.TrimLeft(line, " ")
+	indent := len(line) - len(content)
+	if content == "" || strings.HasPrefix(content, "#") {
+		return
+	}
+	if p.skipIndent >= 0 {
+		if indent > p.skipIndent {
+			return
+		}
+		p.skipIndent = -1
+	}
+	if indent == 0 && (content == "---" || strings.HasPrefix(content, "--- ") || content == "..." || strings.HasPrefix(content, "%")) {
+		return
+	}
+	if p.root == nil {
+		p.root = &positionNode{position: Position{Line: number, Column: indent + 1}}
+		if isFlowCollection(content) {
+			p.flow(p.root, index, indent)
+			p.flowDocument = true
+			return
+		}
+	}
+	p.entry(number, indent, content)
+}
+
+// flow indexes the flow collection starting at the column of the line with
+// the given index as the value of the node.
+func (p *positionParser) flow(node *positionNode, index int, column int) {
+	scanner := &flowScanner{lines: p.lines, firstLine: p.firstLine, line: index, column: column}
+	scanner.value(node)
+}
+
+func (p *positionParser) entry(number int, indent int, content string) {
+	dash := content == "-" || strings.HasPrefix(content, "- ")
+	if p.pending != nil {
+		node := p.pending
+		p.pending = nil
+		if indent > p.pendingIndent || (dash && indent == p.pendingIndent && !p.pendingStrict) {
+			p.stack = append(p.stack, &positionFrame{indent: indent, node: node, sequence: dash})
+		}
+	}
+	for len(p.stack) > 0 {
+		top := p.stack[len(p.stack)-1]
+		if top.indent > indent || (top.indent == indent && top.sequence && !dash) {
+			p.stack = p.stack[:len(p.stack)-1]
+			continue
+		}
+		break
+	}
+	if len(p.stack) == 0 {
+		p.stack = append(p.stack, &positionFrame{indent: indent, node: p.root, sequence: dash})
+	}
+	top := p.stack[len(p.stack)-1]
+	if top.indent != indent || top.sequence != dash {
+		// continuation of a multi-line scalar or invalid indentation
+		return
+	}
+	if dash {
+		p.item(number, indent, top, content)
+		return
+	}
+	p.key(number, indent, top, content)
+}
+
+func (p *positionParser) item(number int, indent int, top *positionFrame, content string) {
+	rest := strings.TrimPrefix(content[1:], " ")
+	node := top.node.child(fmt.Sprintf("[%d]", top.next), Position{Line: number, Column: indent + 1})
+	top.next++
+	restIndent := indent + 2
+	switch {
+	case rest == "" || isNodeProperty(rest):
+		p.setPending(node, indent, true)
+	case rest == "-" || strings.HasPrefix(rest, "- "):
+		p.stack = append(p.stack, &positionFrame{indent: restIndent, node: node, sequence: true})
+		p.entry(number, restIndent, rest)
+	case isMappingKey(rest):
+		p.stack = append(p.stack, &positionFrame{indent: restIndent, node: node})
+		p.entry(number, restIndent, rest)
+	case isFlowCollection(rest):
+		p.flow(node, number-p.firstLine, indent+len(content)-len(rest))
+		p.skipIndent = indent
+	default:
+		node.scalar = isScalar(rest)
+		p.skipIndent = indent
+	}
+}
+
+func (p *positionParser) key(number int, indent int, top *positionFrame, content string) {
+	key, value, ok := splitMappingKey(content)
+	if !ok {
+		return
+	}
+	node := top.node.child(key, Position{Line: number, Column: indent + 1})
+	// the value ends the line except trailing spaces before removing comments
+	column := indent + len(strings.TrimRight(content, " \t")) - len(value)
+	if value = stripComment(value); value == "" || isNodeProperty(value) {
+		p.setPending(node, indent, false)
+		return
+	}
+	if isFlowCollection(value) {
+		p.flow(node, number-p.firstLine, column)
+	}
+	node.scalar = isScalar(value)
+	p.skipIndent = indent
+}
+
+func (p *positionParser) setPending(node *positionNode, indent int, strict bool) {
+	p.pending = node
+	p.pendingIndent = indent
+	p.pendingStrict = strict
+}
+
+var mappingKeyRegexp = regexp.MustCompile(`^("(?:[^"\\]|\\.)*"|'(?:[^']|'')*'|[^'"#{\[\]}>|&*!%@` + "`" + `][^#]*?)\s*:(?:\s|$)`)
+
+// splitMappingKey splits a "key: value" line into the unquoted key and the value.
+func splitMappingKey(content string) (string, string, bool) {
+	match := mappingKeyRegexp.FindStringSubmatchIndex(content)
+	if match == nil {
+		return "", "", false
+	}
+	key := strings.TrimSpace(content[match[2]:match[3]])
+	if strings.HasPrefix(key, "\"") {
+		if unquoted, err := strconv.Unquote(key); err == nil {
+			key = unquoted
+		}
+	} else if strings.HasPrefix(key, "'") {
+		key = strings.Replace(key[1:len(key)-1], "''", "'", -1)
+	}
+	return key, strings.TrimSpace(content[match[1]:]), true
+}
+
+func isMappingKey(content string) bool {
+	_, _, ok := splitMappingKey(content)
+	return ok
+}
+
+// isScalar returns true if the value on the line of a key or item is a scalar
+// and not a flow collection, an alias or a node property.
+func isScalar(value string) bool {
+	return !strings.ContainsAny(value[:1], "{[*&!")
+}
+
+// isFlowCollection returns true if the value is a flow mapping or sequence.
+func isFlowCollection(value string) bool {
+	return strings.HasPrefix(value, "{") || strings.HasPrefix(value, "[")
+}
+
+// isNodeProperty returns true for a lone anchor or tag, the value follows on the next lines.
+func isNodeProperty(value string) bool {
+	return (strings.HasPrefix(value, "&") || strings.HasPrefix(value, "!")) && !strings.Contains(value, " ")
+}
+
+// stripComment removes a trailing comment from a plain value.
+func stripComment(value string) string {
+	if strings.HasPrefix(value, "#") {
+		return ""
+	}
+	if i := strings.Index(value, " #"); i >= 0 && !strings.HasPrefix(value, "\"") && !strings.HasPrefix(value, "'") {
+		return strings.TrimSpace(value[:i])
+	}
+	return value
+}
+
+// flowScanner indexes a flow collection of YAML, which may span several
+// lines, like a JSON document.
+type flowScanner struct {
+	lines     []string
+	firstLine int
+	// line is the index of the current line, column the byte offset in it.
+	line   int
+	column int
+}
+
+func (s *flowScanner) position() Position {
+	return Position{Line: s.firstLine + s.line, Column: s.column + 1}
+}
+
+// peek returns the next character after spaces, line breaks and comments or
+// 0 at the end of the document.
+func (s *flowScanner) peek() byte {
+	for s.line < len(s.lines) {
+		line := s.lines[s.line]
+		for s.column < len(line) && (line[s.column] == ' ' || line[s.column] == '\t') {
+			s.column++
+		}
+		if s.column < len(line) && line[s.column] != '#' {
+			return line[s.column]
+		}
+		s.line++
+		s.column = 0
+	}
+	return 0
+}
+
+// value indexes the value at the current position as the value of the node.
+func (s *flowScanner) value(node *positionNode) {
+	switch s.peek() {
+	case '{':
+		s.column++
+		s.mapping(node)
+	case '[':
+		s.column++
+		s.sequence(node)
+	case 0, ',', '}', ']':
+		node.scalar = true
+	default:
+		s.scalar()
+		node.scalar = true
+	}
+}
+
+func (s *flowScanner) mapping(node *positionNode) {
+	for {
+		switch s.peek() {
+		case 0:
+			return
+		case '}':
+			s.column++
+			return
+		case ',':
+			s.column++
+			continue
+		}
+		position := s.position()
+		key := s.scalar()
+		child := node.child(key, position)
+		if s.peek() == ':' {
+			s.column++
+			s.value(child)
+		} else {
+			child.scalar = true
+		}
+		s.skipInvalid(position)
+	}
+}
+
+func (s *flowScanner) sequence(node *positionNode) {
+	for index := 0; ; index++ {
+		switch s.peek() {
+		case 0:
+			return
+		case ']':
+			s.column++
+			return
+		case ',':
+			s.column++
+			index--
+			continue
+		}
+		position := s.position()
+		s.value(node.child(fmt.Sprintf("[%d]", index), position))
+		s.skipInvalid(position)
+	}
+}
+
+// skipInvalid skips a character if nothing was read since the position, so
+// invalid content like a stray colon does not stop the scanner.
+func (s *flowScanner) skipInvalid(position Position) {
+	if s.position() == position && s.peek() != 0 {
+		s.column++
+	}
+}
+
+// scalar reads the quoted or plain scalar at the current position and
+// returns it unquoted. Plain scalars end at flow indicators and at colons
+// followed by a space.
+func (s *flowScanner) scalar() string {
+	line := s.lines[s.line]
+	start := s.column
+	switch line[start] {
+	case '"':
+		for s.column++; s.column < len(line) && line[s.column] != '"'; s.column++ {
+			if line[s.column] == '\\' {
+				s.column++
+			}
+		}
+		s.column = s.quoteEnd()
+		if unquoted, err := strconv.Unquote(line[start:s.column]); err == nil {
+			return unquoted
+		}
+		return strings.Trim(line[start:s.column], `"`)
+	case '\'':
+		for s.column++; s.column < len(line); s.column++ {
+			if line[s.column] == '\'' {
+				if s.column+1 < len(line) && line[s.column+1] == '\'' {
+					s.column++
+					continue
+				}
+				break
+			}
+		}
+		s.column = s.quoteEnd()
+		return strings.Replace(strings.Trim(line[start:s.column], "'"), "''", "'", -1)
+	}
+	for ; s.column < len(line); s.column++ {
+		c := line[s.column]
+		if strings.IndexByte(",[]{}", c) >= 0 || (c == '#' && line[s.column-1] == ' ') {
+			break
+		}
+		if c == ':' && (s.column+1 == len(line) || strings.IndexByte(" \t,[]{}", line[s.column+1]) >= 0) {
+			break
+		}
+	}
+	return strings.TrimSpace(line[start:s.column])
+}
+
+// quoteEnd returns the offset after the closing quote at the current
+// position, which is the end of the line for unterminated quotes.
+func (s *flowScanner) quoteEnd() int {
+	if s.column < len(s.lines[s.line]) {
+		return s.column + 1
+	}
+	return len(s.lines[s.line])
+}
+
+var yamlErrorLineRegexp = regexp.MustCompile(`line (\d+):`)
+
+// yamlErrorLine returns the line number reported in a YAML parser error relative to the document.
+func yamlErrorLine(err error) (int, bool) {
+	match := yamlErrorLineRegexp.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 0, false
+	}
+	line, err := strconv.Atoi(match[1])
+	return line, err == nil
+}
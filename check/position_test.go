@@ -0,0 +1,180 @@
+package check_test
+
+import (
+	"github.com/seibert-media/k8s-manifest-check/check"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Position", func() {
+	var report *check.Report
+	BeforeEach(func() {
+		report = &check.Report{}
+		check.Content(report, "deploy.yaml", []byte(`# config map first
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  script: |
+    #!/bin/sh
+    name: not-a-key
+  "quoted.key": value
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels: {app: web}
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: "ubuntu:14.04"
+        args:
+        - -v
+        - >-
+          folded
+        resources:
+          limits:
+            cpu: 100m # comment
+            memory: 100Mi
+          requests:
+            cpu: 200m
+            memory: 100Mi
+      - name: sidecar
+        image: "ubuntu:14.04"
+`))
+	})
+	It("return position of fields", func() {
+		Expect(report.Objects).To(HaveLen(2))
+		position, ok := report.Objects[0].Position("data.quoted.key")
+		Expect(ok).To(BeTrue())
+		Expect(position).To(Equal(check.Position{Line: 10, Column: 3}))
+		position, _ = report.Objects[1].Position("spec.template.spec.containers[0].resources.requests.cpu")
+		Expect(position).To(Equal(check.Position{Line: 32, Column: 13}))
+		position, _ = report.Objects[1].Position("spec.template.spec.containers[1].image")
+		Expect(position).To(Equal(check.Position{Line: 35, Column: 9}))
+	})
+	It("skip block scalars", func() {
+		position, _ := report.Objects[0].Position("data.name")
+		Expect(position).To(Equal(check.Position{Line: 6, Column: 1}))
+		position, _ = report.Objects[1].Position("spec.template.spec.containers[0].args[1]")
+		Expect(position).To(Equal(check.Position{Line: 25, Column: 9}))
+		position, _ = report.Objects[1].Position("spec.template.spec.containers[0].resources")
+		Expect(position).To(Equal(check.Position{Line: 27, Column: 9}))
+	})
+	It("return position of nearest parent for missing fields", func() {
+		position, _ := report.Objects[1].Position("spec.template.spec.containers[1].resources.limits.cpu")
+		Expect(position).To(Equal(check.Position{Line: 34, Column: 7}))
+		position, _ = report.Objects[1].Position("metadata.labels.tier")
+		Expect(position).To(Equal(check.Position{Line: 16, Column: 3}))
+	})
+	It("return start of object for empty path", func() {
+		position, _ := report.Objects[0].Position("")
+		Expect(position).To(Equal(check.Position{Line: 2, Column: 1}))
+		position, _ = report.Objects[1].Position("")
+		Expect(position).To(Equal(check.Position{Line: 12, Column: 1}))
+	})
+	It("set position in findings", func() {
+		var finding check.Finding
+		for _, f := range report.Findings {
+			if f.Rule == "cpu-request-within-limit" {
+				finding = f
+			}
+		}
+		Expect(finding.Field).To(Equal("spec.template.spec.containers[0].resources.requests.cpu"))
+		Expect(finding.Line).To(Equal(32))
+		Expect(finding.Column).To(Equal(13))
+	})
+	It("return position of keys with dots", func() {
+		report = &check.Report{}
+		check.Content(report, "secret.yaml", []byte(`apiVersion: v1
+kind: Secret
+metadata:
+  name: config
+  labels:
+    app: web
+    app.kubernetes.io/name: web
+  annotations:
+    k8s-manifest-check.seibert-media.net/ignore: cpu-request-nonzero
+stringData:
+  config:
+    yaml: nested
+  config.yaml: dotted
+`))
+		Expect(report.Objects).To(HaveLen(1))
+		position, _ := report.Objects[0].Position("metadata.annotations.k8s-manifest-check.seibert-media.net/ignore")
+		Expect(position).To(Equal(check.Position{Line: 9, Column: 5}))
+		position, _ = report.Objects[0].Position("metadata.labels.app.kubernetes.io/name")
+		Expect(position).To(Equal(check.Position{Line: 7, Column: 5}))
+		position, _ = report.Objects[0].Position("metadata.labels.app.kubernetes.io/version")
+		Expect(position).To(Equal(check.Position{Line: 5, Column: 3}))
+		position, _ = report.Objects[0].Position("stringData.config.yaml")
+		Expect(position).To(Equal(check.Position{Line: 13, Column: 3}))
+		position, _ = report.Objects[0].Position("stringData.config")
+		Expect(position).To(Equal(check.Position{Line: 11, Column: 3}))
+	})
+	It("return position of fields in JSON documents", func() {
+		report = &check.Report{}
+		check.Content(report, "pod.json", []byte(`{
+  "apiVersion": "v1",
+  "kind": "Pod",
+  "metadata": {"name": "hello-world", "labels": {"app.kubernetes.io/name": "hello"}},
+  "spec": {
+    "containers": [
+      {"name": "init", "image": "busybox:1.36"},
+      {
+        "name": "hello",
+        "image": "ubuntu:14.04",
+        "resources": {"limits": {"cpu": "100m"}}
+      }
+    ]
+  }
+}
+`))
+		Expect(report.Objects).To(HaveLen(1))
+		position, _ := report.Objects[0].Position("")
+		Expect(position).To(Equal(check.Position{Line: 1, Column: 1}))
+		position, _ = report.Objects[0].Position("metadata.labels.app.kubernetes.io/name")
+		Expect(position).To(Equal(check.Position{Line: 4, Column: 50}))
+		position, _ = report.Objects[0].Position("spec.containers[0].image")
+		Expect(position).To(Equal(check.Position{Line: 7, Column: 24}))
+		position, _ = report.Objects[0].Position("spec.containers[1]")
+		Expect(position).To(Equal(check.Position{Line: 8, Column: 7}))
+		position, _ = report.Objects[0].Position("spec.containers[1].resources.limits.cpu")
+		Expect(position).To(Equal(check.Position{Line: 11, Column: 34}))
+		position, _ = report.Objects[0].Position("spec.containers[1].resources.requests.cpu")
+		Expect(position).To(Equal(check.Position{Line: 11, Column: 9}))
+	})
+	It("return position of fields in flow collections", func() {
+		report = &check.Report{}
+		check.Content(report, "pod.yaml", []byte(`apiVersion: v1
+kind: Pod
+metadata:
+  name: hello-world
+spec:
+  containers:
+  - {name: init, image: "busybox:1.36"}
+  - name: hello
+    args: [
+      "--port=8080", # comment
+      '--name=a, b', -v]
+    image: "ubuntu:14.04"
+`))
+		Expect(report.Objects).To(HaveLen(1))
+		position, _ := report.Objects[0].Position("spec.containers[0].image")
+		Expect(position).To(Equal(check.Position{Line: 7, Column: 18}))
+		position, _ = report.Objects[0].Position("spec.containers[1].args[2]")
+		Expect(position).To(Equal(check.Position{Line: 11, Column: 22}))
+		position, _ = report.Objects[0].Position("spec.containers[1].image")
+		Expect(position).To(Equal(check.Position{Line: 12, Column: 5}))
+	})
+	It("set line of parse errors", func() {
+		report = &check.Report{}
+		check.Content(report, "broken.yaml", []byte("apiVersion: v1\nkind: ConfigMap\n---\napiVersion: v1\nkind: ConfigMap\ndata:\n  a: b\n c: d\n"))
+		Expect(report.Findings).To(HaveLen(1))
+		Expect(report.Findings[0].Line).To(Equal(7))
+	})
+})
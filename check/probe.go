@@ -0,0 +1,268 @@
+package check
+
+import (
+	"fmt"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Rule IDs of the probe checks.
+const (
+	ReadinessProbeRule = "readiness-probe"
+	LivenessProbeRule  = "liveness-probe"
+	ProbePortRule      = "probe-port"
+	ProbeTimingRule    = "probe-timing"
+	ProbeIdenticalRule = "probe-identical"
+)
+
+func init() {
+	for _, rule := range probeRules {
+		Register(rule)
+	}
+	DefaultRegistry.Disable(ReadinessProbeRule)
+	DefaultRegistry.Disable(LivenessProbeRule)
+}
+
+// longRunningKinds are the workloads, whose pods are expected to run until
+// they are replaced, in contrast to Jobs and CronJobs.
+var longRunningKinds = []string{"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController"}
+
+// probeSpec is the part of a pod spec with the probes of the containers.
+// It is decoded from the document because the vendored API types lack
+// startup and gRPC probes. Init containers have no probes.
+type probeSpec struct {
+	Containers []probeContainer `json:"containers"`
+}
+
+type probeContainer struct {
+	Name           string                 `json:"name"`
+	Ports          []corev1.ContainerPort `json:"ports"`
+	LivenessProbe  *probe                 `json:"livenessProbe"`
+	ReadinessProbe *probe                 `json:"readinessProbe"`
+	StartupProbe   *probe                 `json:"startupProbe"`
+}
+
+type probe struct {
+	probeHandler
+	InitialDelaySeconds int32 `json:"initialDelaySeconds"`
+	TimeoutSeconds      int32 `json:"timeoutSeconds"`
+	PeriodSeconds       int32 `json:"periodSeconds"`
+	SuccessThreshold    int32 `json:"successThreshold"`
+	FailureThreshold    int32 `json:"failureThreshold"`
+}
+
+// probeHandler is the action of a probe.
+type probeHandler struct {
+	Exec      *corev1.ExecAction      `json:"exec"`
+	HTTPGet   *corev1.HTTPGetAction   `json:"httpGet"`
+	TCPSocket *corev1.TCPSocketAction `json:"tcpSocket"`
+	GRPC      *struct {
+		Port    int32   `json:"port"`
+		Service *string `json:"service"`
+	} `json:"grpc"`
+}
+
+// withDefaults returns the probe with the defaults of the API server set.
+func (p probe) withDefaults() probe {
+	if p.TimeoutSeconds == 0 {
+		p.TimeoutSeconds = 1
+	}
+	if p.PeriodSeconds == 0 {
+		p.PeriodSeconds = 10
+	}
+	if p.SuccessThreshold == 0 {
+		p.SuccessThreshold = 1
+	}
+	if p.FailureThreshold == 0 {
+		p.FailureThreshold = 3
+	}
+	return p
+}
+
+// port returns the port of the probe and the field it is defined in.
+func (p probe) port() (intstr.IntOrString, string, bool) {
+	switch {
+	case p.HTTPGet != nil:
+		return p.HTTPGet.Port, "httpGet", true
+	case p.TCPSocket != nil:
+		return p.TCPSocket.Port, "tcpSocket", true
+	case p.GRPC != nil:
+		return intstr.FromInt(int(p.GRPC.Port)), "grpc", true
+	}
+	return intstr.IntOrString{}, "", false
+}
+
+// namedProbe is a probe of a container together with its field name.
+type namedProbe struct {
+	field string
+	probe *probe
+}
+
+func (c probeContainer) probes() []namedProbe {
+	var result []namedProbe
+	for _, p := range []namedProbe{{"livenessProbe", c.LivenessProbe}, {"readinessProbe", c.ReadinessProbe}, {"startupProbe", c.StartupProbe}} {
+		if p.probe != nil {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+var probeRules = []*probeRule{
+	{
+		id:          ReadinessProbeRule,
+		severity:    SeverityError,
+		description: "every container of long-running workloads has a readiness probe",
+		longRunning: true,
+		check: func(c probeContainer, path *field.Path) []Finding {
+			if c.ReadinessProbe == nil {
+				return []Finding{{Field: path.String(), Message: "readinessProbe is missing"}}
+			}
+			return nil
+		},
+	},
+	{
+		id:          LivenessProbeRule,
+		severity:    SeverityWarning,
+		description: "every container of long-running workloads has a liveness probe",
+		longRunning: true,
+		check: func(c probeContainer, path *field.Path) []Finding {
+			if c.LivenessProbe == nil {
+				return []Finding{{Field: path.String(), Message: "livenessProbe is missing"}}
+			}
+			return nil
+		},
+	},
+	{
+		id:          ProbePortRule,
+		severity:    SeverityError,
+		description: "probes reference a declared container port by name or number",
+		check: func(c probeContainer, path *field.Path) []Finding {
+			var findings []Finding
+			for _, p := range c.probes() {
+				port, action, ok := p.probe.port()
+				if !ok || declaredPort(c.Ports, port) {
+					continue
+				}
+				findings = append(findings, Finding{
+					Field:   path.Child(p.field, action, "port").String(),
+					Message: fmt.Sprintf("port %s of %s is not a declared container port", port.String(), p.field),
+				})
+			}
+			return findings
+		},
+	},
+	{
+		id:          ProbeTimingRule,
+		severity:    SeverityWarning,
+		description: "timeouts, periods and thresholds of probes are consistent",
+		check: func(c probeContainer, path *field.Path) []Finding {
+			var findings []Finding
+			for _, p := range c.probes() {
+				probe := p.probe.withDefaults()
+				if probe.TimeoutSeconds >= probe.PeriodSeconds {
+					findings = append(findings, Finding{
+						Field:   path.Child(p.field, "timeoutSeconds").String(),
+						Message: fmt.Sprintf("timeoutSeconds %d of %s must be less than periodSeconds %d", probe.TimeoutSeconds, p.field, probe.PeriodSeconds),
+					})
+				}
+				if p.field != "readinessProbe" && probe.SuccessThreshold != 1 {
+					findings = append(findings, Finding{
+						Field:   path.Child(p.field, "successThreshold").String(),
+						Message: fmt.Sprintf("successThreshold of %s must be 1", p.field),
+					})
+				}
+			}
+			if c.LivenessProbe != nil && c.LivenessProbe.withDefaults().FailureThreshold == 1 {
+				findings = append(findings, Finding{
+					Field:   path.Child("livenessProbe", "failureThreshold").String(),
+					Message: "failureThreshold 1 of livenessProbe restarts the container on a single failure",
+				})
+			}
+			if c.LivenessProbe != nil && c.ReadinessProbe != nil {
+				liveness, readiness := c.LivenessProbe.withDefaults(), c.ReadinessProbe.withDefaults()
+				if liveness.FailureThreshold*liveness.PeriodSeconds < readiness.FailureThreshold*readiness.PeriodSeconds {
+					findings = append(findings, Finding{
+						Field:   path.Child("livenessProbe").String(),
+						Message: fmt.Sprintf("livenessProbe fails after %ds before readinessProbe fails after %ds", liveness.FailureThreshold*liveness.PeriodSeconds, readiness.FailureThreshold*readiness.PeriodSeconds),
+					})
+				}
+			}
+			return findings
+		},
+	},
+	{
+		id:          ProbeIdenticalRule,
+		severity:    SeverityWarning,
+		description: "liveness probes differ from readiness probes",
+		check: func(c probeContainer, path *field.Path) []Finding {
+			if c.LivenessProbe != nil && c.ReadinessProbe != nil && reflect.DeepEqual(c.LivenessProbe.probeHandler, c.ReadinessProbe.probeHandler) {
+				return []Finding{{Field: path.Child("livenessProbe").String(), Message: "livenessProbe is identical to readinessProbe"}}
+			}
+			return nil
+		},
+	},
+}
+
+// declaredPort returns true if the port is the name or number of a container port.
+func declaredPort(ports []corev1.ContainerPort, port intstr.IntOrString) bool {
+	for _, declared := range ports {
+		if port.Type == intstr.String && declared.Name == port.StrVal {
+			return true
+		}
+		if port.Type == intstr.Int && declared.ContainerPort == port.IntVal {
+			return true
+		}
+	}
+	return false
+}
+
+// probeRule checks the probes of every container of a workload.
+type probeRule struct {
+	id          string
+	severity    Severity
+	description string
+	// longRunning restricts the rule to long-running workloads.
+	longRunning bool
+	// check returns the findings of the container at path.
+	check func(container probeContainer, path *field.Path) []Finding
+}
+
+func (p *probeRule) ID() string {
+	return p.id
+}
+
+func (p *probeRule) Description() string {
+	return p.description
+}
+
+func (p *probeRule) Severity() Severity {
+	return p.severity
+}
+
+func (p *probeRule) Applies(obj *Object) bool {
+	return obj.Template != nil && (!p.longRunning || contains(longRunningKinds, obj.Kind))
+}
+
+func (p *probeRule) Check(obj *Object) []Finding {
+	if !p.Applies(obj) {
+		return nil
+	}
+	spec := &probeSpec{}
+	if !obj.decodePodSpec(spec) {
+		return nil
+	}
+	var findings []Finding
+	for i, container := range spec.Containers {
+		path := obj.PodSpecPath().Child(RegularContainerType.Field()).Index(i)
+		for _, finding := range p.check(container, path) {
+			finding.ContainerType = RegularContainerType
+			finding.Container = container.Name
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
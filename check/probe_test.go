@@ -0,0 +1,127 @@
+package check_test
+
+import (
+	"fmt"
+
+	"github.com/seibert-media/k8s-manifest-check/check"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// workloadWithProbes is a workload with placeholders for kind and the
+// probes of its container.
+const workloadWithProbes = `apiVersion: apps/v1
+kind: %s
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: "web:1.0"
+        ports:
+        - name: http
+          containerPort: 8080
+        - containerPort: 9090
+%s`
+
+var _ = Describe("Probes", func() {
+	var checker *check.Checker
+	findings := func(kind, probes string) []string {
+		return checkFindings(checker, "deploy.yaml", fmt.Sprintf(workloadWithProbes, kind, probes))
+	}
+	BeforeEach(func() {
+		checker = checkerWithRules(check.ReadinessProbeRule, check.LivenessProbeRule, check.ProbePortRule, check.ProbeTimingRule, check.ProbeIdenticalRule)
+	})
+	It("check missing probes only if enabled", func() {
+		Expect(check.DefaultRegistry.Enabled(check.ReadinessProbeRule)).To(BeFalse())
+		Expect(check.DefaultRegistry.Enabled(check.LivenessProbeRule)).To(BeFalse())
+		Expect(check.DefaultRegistry.Enabled(check.ProbePortRule)).To(BeTrue())
+	})
+	It("report nothing for distinct probes of declared ports", func() {
+		Expect(findings("Deployment", `        readinessProbe:
+          httpGet:
+            path: /ready
+            port: http
+        livenessProbe:
+          tcpSocket:
+            port: 9090
+          periodSeconds: 20
+        startupProbe:
+          grpc:
+            port: 9090
+          failureThreshold: 30
+`)).To(BeEmpty())
+	})
+	It("report missing probes of long-running workloads only", func() {
+		Expect(findings("StatefulSet", "")).To(Equal([]string{
+			"readinessProbe is missing in deploy.yaml:9:7 (document 1, StatefulSet web, container web) [readiness-probe]",
+			"livenessProbe is missing in deploy.yaml:9:7 (document 1, StatefulSet web, container web) [liveness-probe]",
+		}))
+		report := &check.Report{}
+		checker.Content(report, "job.yaml", []byte(`apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+spec:
+  template:
+    spec:
+      containers:
+      - name: migrate
+        image: "migrate:1.0"
+`))
+		Expect(report.Findings).To(BeEmpty())
+	})
+	It("report probe ports not declared by the container", func() {
+		Expect(findings("Deployment", `        readinessProbe:
+          httpGet:
+            path: /ready
+            port: web
+        livenessProbe:
+          tcpSocket:
+            port: 8081
+        startupProbe:
+          grpc:
+            port: 9091
+`)).To(Equal([]string{
+			"port 8081 of livenessProbe is not a declared container port in deploy.yaml:21:13 (document 1, Deployment web, container web) [probe-port]",
+			"port web of readinessProbe is not a declared container port in deploy.yaml:18:13 (document 1, Deployment web, container web) [probe-port]",
+			"port 9091 of startupProbe is not a declared container port in deploy.yaml:24:13 (document 1, Deployment web, container web) [probe-port]",
+		}))
+	})
+	It("report inconsistent timing", func() {
+		Expect(findings("Deployment", `        readinessProbe:
+          httpGet:
+            path: /ready
+            port: http
+          timeoutSeconds: 10
+          failureThreshold: 6
+        livenessProbe:
+          httpGet:
+            path: /healthz
+            port: http
+          failureThreshold: 1
+          successThreshold: 2
+`)).To(Equal([]string{
+			"successThreshold of livenessProbe must be 1 in deploy.yaml:26:11 (document 1, Deployment web, container web) [probe-timing]",
+			"timeoutSeconds 10 of readinessProbe must be less than periodSeconds 10 in deploy.yaml:19:11 (document 1, Deployment web, container web) [probe-timing]",
+			"failureThreshold 1 of livenessProbe restarts the container on a single failure in deploy.yaml:25:11 (document 1, Deployment web, container web) [probe-timing]",
+			"livenessProbe fails after 10s before readinessProbe fails after 60s in deploy.yaml:21:9 (document 1, Deployment web, container web) [probe-timing]",
+		}))
+	})
+	It("report liveness probes identical to readiness probes", func() {
+		Expect(findings("DaemonSet", `        readinessProbe:
+          httpGet:
+            path: /healthz
+            port: http
+        livenessProbe:
+          httpGet:
+            path: /healthz
+            port: http
+          periodSeconds: 20
+`)).To(Equal([]string{
+			"livenessProbe is identical to readinessProbe in deploy.yaml:19:9 (document 1, DaemonSet web, container web) [probe-identical]",
+		}))
+	})
+})
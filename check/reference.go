@@ -0,0 +1,371 @@
+package check
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Rule IDs of the reference checks.
+const (
+	ConfigMapReferenceRule      = "reference-configmap"
+	SecretReferenceRule         = "reference-secret"
+	ClaimReferenceRule          = "reference-pvc"
+	ServiceAccountReferenceRule = "reference-service-account"
+	ServiceSelectorRule         = "reference-service-selector"
+	IngressBackendRule          = "reference-ingress-backend"
+)
+
+func init() {
+	for _, rule := range referenceRules {
+		Register(rule)
+		// objects may be managed elsewhere, so references are only checked on demand
+		DefaultRegistry.Disable(rule.ID())
+	}
+}
+
+// implicitObjects are created by the cluster in every namespace and are
+// therefore never missing.
+var implicitObjects = map[string][]string{
+	"ConfigMap":      {"kube-root-ca.crt"},
+	"ServiceAccount": {"default"},
+}
+
+var referenceRules = []*referenceRule{
+	{
+		id:          ConfigMapReferenceRule,
+		description: "ConfigMaps referenced by pod templates and their keys exist in the checked manifests",
+		check:       podReferenceCheck("ConfigMap"),
+	},
+	{
+		id:          SecretReferenceRule,
+		description: "Secrets referenced by pod templates and their keys exist in the checked manifests",
+		check:       podReferenceCheck("Secret"),
+	},
+	{
+		id:          ClaimReferenceRule,
+		description: "PersistentVolumeClaims referenced by pod templates exist in the checked manifests",
+		check:       podReferenceCheck("PersistentVolumeClaim"),
+	},
+	{
+		id:          ServiceAccountReferenceRule,
+		description: "ServiceAccounts of pod templates exist in the checked manifests",
+		check:       podReferenceCheck("ServiceAccount"),
+	},
+	{
+		id:          ServiceSelectorRule,
+		description: "selectors of Services match the pod template of a workload in the checked manifests",
+		kind:        "Service",
+		check: func(obj *Object, graph *Graph) []Finding {
+			service, ok := obj.Runtime.(*corev1.Service)
+			if !ok || len(service.Spec.Selector) == 0 {
+				return nil
+			}
+			selector := labels.SelectorFromSet(service.Spec.Selector)
+			for _, workload := range graph.Workloads(obj.Namespace) {
+				if selector.Matches(labels.Set(workload.Template.Labels)) {
+					return nil
+				}
+			}
+			return []Finding{{
+				Field:   "spec.selector",
+				Message: fmt.Sprintf("selector %s matches no pod template", selector.String()),
+			}}
+		},
+	},
+	{
+		id:          IngressBackendRule,
+		description: "backends of Ingresses reference ports of Services in the checked manifests",
+		kind:        "Ingress",
+		check: func(obj *Object, graph *Graph) []Finding {
+			if obj.Kind != "Ingress" {
+				return nil
+			}
+			spec := &ingressSpec{}
+			if !obj.decode(spec, "spec") {
+				return nil
+			}
+			var findings []Finding
+			for _, backend := range spec.backends() {
+				name, port, ok := backend.service()
+				if !ok {
+					continue
+				}
+				target, ok := graph.Lookup("Service", obj.Namespace, name)
+				if !ok {
+					findings = append(findings, Finding{
+						Field:   backend.nameField().String(),
+						Message: fmt.Sprintf("Service %s not found", name),
+					})
+					continue
+				}
+				if service, ok := target.Runtime.(*corev1.Service); ok && !servicePort(service.Spec.Ports, port) {
+					findings = append(findings, Finding{
+						Field:   backend.portField().String(),
+						Message: fmt.Sprintf("Service %s has no port %s", name, port.String()),
+					})
+				}
+			}
+			return findings
+		},
+	},
+}
+
+// reference is a reference of a pod template to an object of the same namespace.
+type reference struct {
+	kind string
+	name string
+	// key of the data of a ConfigMap or Secret, empty if the whole object is referenced.
+	key      string
+	optional bool
+	// path is the field path of the reference, nameField and keyField are its fields of the name and the key.
+	path      *field.Path
+	nameField string
+	keyField  string
+	// container is nil for references of the pod spec.
+	container *Container
+}
+
+// podReferenceCheck returns the check of the references of pod templates to
+// objects of the kind. Optional references are not checked.
+func podReferenceCheck(kind string) func(obj *Object, graph *Graph) []Finding {
+	return func(obj *Object, graph *Graph) []Finding {
+		var findings []Finding
+		for _, ref := range podReferences(obj) {
+			if ref.kind != kind || ref.optional || contains(implicitObjects[kind], ref.name) {
+				continue
+			}
+			finding := Finding{}
+			target, ok := graph.Lookup(kind, obj.Namespace, ref.name)
+			if !ok {
+				finding.Field = ref.path.Child(ref.nameField).String()
+				finding.Message = fmt.Sprintf("%s %s not found", kind, ref.name)
+			} else if ref.key != "" && !contains(target.dataKeys(), ref.key) {
+				finding.Field = ref.path.Child(ref.keyField).String()
+				finding.Message = fmt.Sprintf("%s %s has no key %s", kind, ref.name, ref.key)
+			} else {
+				continue
+			}
+			if ref.container != nil {
+				finding.ContainerType = ref.container.Type
+				finding.Container = ref.container.Name
+			}
+			findings = append(findings, finding)
+		}
+		return findings
+	}
+}
+
+// podReferences returns the references of the pod template of the object to
+// ConfigMaps, Secrets, PersistentVolumeClaims and its ServiceAccount.
+func podReferences(obj *Object) []reference {
+	if obj.Template == nil {
+		return nil
+	}
+	spec := obj.Template.Spec
+	specPath := obj.PodSpecPath()
+	var result []reference
+	for _, container := range obj.Containers() {
+		container := container
+		for i, source := range container.EnvFrom {
+			path := container.Path.Child("envFrom").Index(i)
+			if source.ConfigMapRef != nil {
+				result = append(result, reference{kind: "ConfigMap", name: source.ConfigMapRef.Name, optional: isTrue(source.ConfigMapRef.Optional),
+					path: path.Child("configMapRef"), nameField: "name", container: &container})
+			}
+			if source.SecretRef != nil {
+				result = append(result, reference{kind: "Secret", name: source.SecretRef.Name, optional: isTrue(source.SecretRef.Optional),
+					path: path.Child("secretRef"), nameField: "name", container: &container})
+			}
+		}
+		for i, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			path := container.Path.Child("env").Index(i).Child("valueFrom")
+			if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+				result = append(result, reference{kind: "ConfigMap", name: ref.Name, key: ref.Key, optional: isTrue(ref.Optional),
+					path: path.Child("configMapKeyRef"), nameField: "name", keyField: "key", container: &container})
+			}
+			if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+				result = append(result, reference{kind: "Secret", name: ref.Name, key: ref.Key, optional: isTrue(ref.Optional),
+					path: path.Child("secretKeyRef"), nameField: "name", keyField: "key", container: &container})
+			}
+		}
+	}
+	for i, volume := range spec.Volumes {
+		path := specPath.Child("volumes").Index(i)
+		switch {
+		case volume.ConfigMap != nil:
+			result = append(result, reference{kind: "ConfigMap", name: volume.ConfigMap.Name, optional: isTrue(volume.ConfigMap.Optional),
+				path: path.Child("configMap"), nameField: "name"})
+		case volume.Secret != nil:
+			result = append(result, reference{kind: "Secret", name: volume.Secret.SecretName, optional: isTrue(volume.Secret.Optional),
+				path: path.Child("secret"), nameField: "secretName"})
+		case volume.PersistentVolumeClaim != nil:
+			result = append(result, reference{kind: "PersistentVolumeClaim", name: volume.PersistentVolumeClaim.ClaimName,
+				path: path.Child("persistentVolumeClaim"), nameField: "claimName"})
+		case volume.Projected != nil:
+			for j, source := range volume.Projected.Sources {
+				sourcePath := path.Child("projected", "sources").Index(j)
+				if source.ConfigMap != nil {
+					result = append(result, reference{kind: "ConfigMap", name: source.ConfigMap.Name, optional: isTrue(source.ConfigMap.Optional),
+						path: sourcePath.Child("configMap"), nameField: "name"})
+				}
+				if source.Secret != nil {
+					result = append(result, reference{kind: "Secret", name: source.Secret.Name, optional: isTrue(source.Secret.Optional),
+						path: sourcePath.Child("secret"), nameField: "name"})
+				}
+			}
+		}
+	}
+	for i, secret := range spec.ImagePullSecrets {
+		result = append(result, reference{kind: "Secret", name: secret.Name, path: specPath.Child("imagePullSecrets").Index(i), nameField: "name"})
+	}
+	if spec.ServiceAccountName != "" {
+		result = append(result, reference{kind: "ServiceAccount", name: spec.ServiceAccountName, path: specPath, nameField: "serviceAccountName"})
+	} else if spec.DeprecatedServiceAccount != "" {
+		result = append(result, reference{kind: "ServiceAccount", name: spec.DeprecatedServiceAccount, path: specPath, nameField: "serviceAccount"})
+	}
+	return result
+}
+
+func isTrue(value *bool) bool {
+	return value != nil && *value
+}
+
+// ingressSpec is the part of the spec of an Ingress with its backends. It is
+// decoded from the document to support extensions/v1beta1 and networking.k8s.io
+// Ingresses, which are missing in the vendored API versions.
+type ingressSpec struct {
+	DefaultBackend *ingressBackend `json:"defaultBackend"`
+	Backend        *ingressBackend `json:"backend"`
+	Rules          []struct {
+		HTTP *struct {
+			Paths []struct {
+				Backend ingressBackend `json:"backend"`
+			} `json:"paths"`
+		} `json:"http"`
+	} `json:"rules"`
+}
+
+// ingressBackend is the backend of networking.k8s.io/v1 with a service or of
+// earlier versions with serviceName and servicePort.
+type ingressBackend struct {
+	ServiceName string             `json:"serviceName"`
+	ServicePort intstr.IntOrString `json:"servicePort"`
+	Service     *struct {
+		Name string `json:"name"`
+		Port struct {
+			Name   string `json:"name"`
+			Number int32  `json:"number"`
+		} `json:"port"`
+	} `json:"service"`
+
+	path *field.Path
+}
+
+// backends returns all backends of the Ingress with their field paths.
+func (s *ingressSpec) backends() []ingressBackend {
+	var result []ingressBackend
+	if s.DefaultBackend != nil {
+		backend := *s.DefaultBackend
+		backend.path = field.NewPath("spec", "defaultBackend")
+		result = append(result, backend)
+	}
+	if s.Backend != nil {
+		backend := *s.Backend
+		backend.path = field.NewPath("spec", "backend")
+		result = append(result, backend)
+	}
+	for i, rule := range s.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for j, path := range rule.HTTP.Paths {
+			backend := path.Backend
+			backend.path = field.NewPath("spec", "rules").Index(i).Child("http", "paths").Index(j).Child("backend")
+			result = append(result, backend)
+		}
+	}
+	return result
+}
+
+// service returns the name and port of the Service of the backend. It is
+// false for backends referencing resources.
+func (b ingressBackend) service() (string, intstr.IntOrString, bool) {
+	if b.Service != nil {
+		if b.Service.Port.Name != "" {
+			return b.Service.Name, intstr.FromString(b.Service.Port.Name), true
+		}
+		return b.Service.Name, intstr.FromInt(int(b.Service.Port.Number)), true
+	}
+	return b.ServiceName, b.ServicePort, b.ServiceName != ""
+}
+
+func (b ingressBackend) nameField() *field.Path {
+	if b.Service != nil {
+		return b.path.Child("service", "name")
+	}
+	return b.path.Child("serviceName")
+}
+
+func (b ingressBackend) portField() *field.Path {
+	if b.Service != nil {
+		return b.path.Child("service", "port")
+	}
+	return b.path.Child("servicePort")
+}
+
+// servicePort returns true if the port is the name or number of a port of the Service.
+func servicePort(ports []corev1.ServicePort, port intstr.IntOrString) bool {
+	for _, servicePort := range ports {
+		if port.Type == intstr.String && servicePort.Name == port.StrVal {
+			return true
+		}
+		if port.Type == intstr.Int && servicePort.Port == port.IntVal {
+			return true
+		}
+	}
+	return false
+}
+
+// referenceRule checks the references of an object to other objects of the run.
+type referenceRule struct {
+	id          string
+	description string
+	// kind of the checked objects, all objects with pod template are checked if it is empty.
+	kind  string
+	check func(obj *Object, graph *Graph) []Finding
+}
+
+func (r *referenceRule) ID() string {
+	return r.id
+}
+
+func (r *referenceRule) Description() string {
+	return r.description
+}
+
+func (r *referenceRule) Severity() Severity {
+	return SeverityError
+}
+
+func (r *referenceRule) Applies(obj *Object) bool {
+	if r.kind == "" {
+		return obj.Template != nil
+	}
+	return obj.Kind == r.kind
+}
+
+// Check returns no findings, references are checked by CheckGraph.
+func (r *referenceRule) Check(obj *Object) []Finding {
+	return nil
+}
+
+func (r *referenceRule) CheckGraph(obj *Object, graph *Graph) []Finding {
+	return r.check(obj, graph)
+}
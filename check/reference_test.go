@@ -0,0 +1,254 @@
+package check_test
+
+import (
+	"github.com/seibert-media/k8s-manifest-check/check"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const referencingDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: shop
+spec:
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      serviceAccountName: web
+      imagePullSecrets:
+      - name: registry
+      containers:
+      - name: web
+        image: "web:1.0"
+        envFrom:
+        - configMapRef:
+            name: web-config
+        - configMapRef:
+            name: feature-flags
+            optional: true
+        env:
+        - name: PASSWORD
+          valueFrom:
+            secretKeyRef:
+              name: web
+              key: pasword
+      volumes:
+      - name: ca
+        configMap:
+          name: kube-root-ca.crt
+      - name: data
+        persistentVolumeClaim:
+          claimName: data
+      - name: tls
+        secret:
+          secretName: web-tls
+`
+
+const referencedObjects = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+data:
+  LOG_LEVEL: info
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: web
+  namespace: shop
+stringData:
+  password: ENC[AES256_GCM,data:Tr7o1Y8=,iv:1=,tag:2=,type:str]
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: web-tls
+  namespace: other
+type: kubernetes.io/tls
+data:
+  tls.crt: ENC[AES256_GCM,data:Tr7o1Y8=,iv:1=,tag:2=,type:str]
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: shop
+spec:
+  selector:
+    app: wbe
+  ports:
+  - name: http
+    port: 80
+    targetPort: 8080
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: external
+  namespace: shop
+spec:
+  type: ExternalName
+  externalName: example.com
+`
+
+const ingresses = `apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+  namespace: shop
+spec:
+  defaultBackend:
+    service:
+      name: web
+      port:
+        name: http
+  rules:
+  - host: shop.example.com
+    http:
+      paths:
+      - path: /
+        pathType: Prefix
+        backend:
+          service:
+            name: web
+            port:
+              number: 8080
+      - path: /api
+        pathType: Prefix
+        backend:
+          service:
+            name: api
+            port:
+              number: 80
+---
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: legacy
+  namespace: shop
+spec:
+  backend:
+    serviceName: web
+    servicePort: 80
+  rules:
+  - http:
+      paths:
+      - backend:
+          serviceName: web
+          servicePort: https
+`
+
+var _ = Describe("References", func() {
+	var checker *check.Checker
+	findings := func(manifests ...string) []string {
+		return checkFindings(checker, "manifest.yaml", manifests...)
+	}
+	BeforeEach(func() {
+		checker = checkerWithRules(check.ConfigMapReferenceRule, check.SecretReferenceRule, check.ClaimReferenceRule, check.ServiceAccountReferenceRule, check.ServiceSelectorRule, check.IngressBackendRule)
+	})
+	It("be disabled by default", func() {
+		for _, id := range []string{check.ConfigMapReferenceRule, check.SecretReferenceRule, check.ClaimReferenceRule, check.ServiceAccountReferenceRule, check.ServiceSelectorRule, check.IngressBackendRule} {
+			Expect(check.DefaultRegistry.Enabled(id)).To(BeFalse(), id)
+		}
+	})
+	It("report missing objects and keys referenced by pod templates", func() {
+		Expect(findings(referencingDeployment, referencedObjects)).To(Equal([]string{
+			"Secret web has no key pasword in manifest.yaml:29:15 (document 1, Deployment shop/web, container web) [reference-secret]",
+			"Secret web-tls not found in manifest.yaml:39:11 (document 1, Deployment shop/web) [reference-secret]",
+			"Secret registry not found in manifest.yaml:14:9 (document 1, Deployment shop/web) [reference-secret]",
+			"PersistentVolumeClaim data not found in manifest.yaml:36:11 (document 1, Deployment shop/web) [reference-pvc]",
+			"ServiceAccount web not found in manifest.yaml:12:7 (document 1, Deployment shop/web) [reference-service-account]",
+			"selector app=wbe matches no pod template in manifest.yaml:31:3 (document 4, Service shop/web) [reference-service-selector]",
+		}))
+	})
+	It("resolve references to objects of all manifests", func() {
+		Expect(findings(referencingDeployment, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+  namespace: shop
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: web
+stringData:
+  pasword: ENC[AES256_GCM,data:Tr7o1Y8=,iv:1=,tag:2=,type:str]
+`, `apiVersion: v1
+kind: Secret
+metadata:
+  name: registry
+  namespace: shop
+type: kubernetes.io/dockerconfigjson
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: web-tls
+  namespace: shop
+type: kubernetes.io/tls
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data
+  namespace: shop
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: web
+  namespace: shop
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: shop
+spec:
+  selector:
+    app: web
+`)).To(BeEmpty())
+	})
+	It("report backends of Ingresses missing Services and ports", func() {
+		Expect(findings(ingresses, referencedObjects)).To(Equal([]string{
+			"Service web has no port 8080 in manifest.yaml:21:13 (document 1, Ingress shop/web) [reference-ingress-backend]",
+			"Service api not found in manifest.yaml:27:13 (document 1, Ingress shop/web) [reference-ingress-backend]",
+			"Service web has no port https in manifest.yaml:45:11 (document 2, Ingress shop/legacy) [reference-ingress-backend]",
+			"selector app=wbe matches no pod template in manifest.yaml:31:3 (document 4, Service shop/web) [reference-service-selector]",
+		}))
+	})
+	It("suppress findings by annotations", func() {
+		report := &check.Report{}
+		checker.Content(report, "service.yaml", []byte(`apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  annotations:
+    k8s-manifest-check.seibert-media.net/ignore: reference-service-selector
+spec:
+  selector:
+    app: web
+`))
+		checker.References(report)
+		Expect(report.Findings).To(BeEmpty())
+		Expect(report.Suppressed).To(HaveLen(1))
+	})
+	It("keep findings ordered by file", func() {
+		report := &check.Report{}
+		checker.Content(report, "a.yaml", []byte("apiVersion: v1\nkind: Service\nmetadata:\n  name: web\nspec:\n  selector:\n    app: web\n"))
+		checker.Content(report, "b.yaml", []byte("kind: [\n"))
+		checker.References(report)
+		Expect(report.Findings).To(HaveLen(2))
+		Expect(report.Findings[0].Rule).To(Equal(check.ServiceSelectorRule))
+		Expect(report.Findings[1].Rule).To(Equal(check.ParseRule))
+	})
+	It("check nothing before all objects are known", func() {
+		report := &check.Report{}
+		checker.Content(report, "manifest.yaml", []byte(referencingDeployment))
+		Expect(report.Findings).To(BeEmpty())
+	})
+})
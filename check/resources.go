@@ -0,0 +1,225 @@
+package check
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// The resource rules are registered by a variable initializer, which runs
+// before the init functions of all files, so they stay the first rules of the
+// default registry and of its findings.
+var _ = registerResourceRules()
+
+func registerResourceRules() bool {
+	for _, rule := range resourceRules {
+		Register(rule)
+	}
+	for _, rule := range maxLimitRules {
+		Register(rule)
+		DefaultRegistry.Disable(rule.ID())
+	}
+	return true
+}
+
+var resourceRules = []*resourceRule{
+	{
+		id:          "cpu-request-nonzero",
+		severity:    SeverityError,
+		field:       []string{"requests", "cpu"},
+		description: "cpu request of every container is set to a none zero value",
+		check: func(resources corev1.ResourceRequirements) string {
+			if resources.Requests.Cpu().IsZero() {
+				return "cpu request is zero"
+			}
+			return ""
+		},
+	},
+	{
+		id:          "memory-request-nonzero",
+		severity:    SeverityError,
+		field:       []string{"requests", "memory"},
+		description: "memory request of every container is set to a none zero value",
+		check: func(resources corev1.ResourceRequirements) string {
+			if resources.Requests.Memory().IsZero() {
+				return "memory request is zero"
+			}
+			return ""
+		},
+	},
+	{
+		id:          "memory-limit-nonzero",
+		severity:    SeverityWarning,
+		field:       []string{"limits", "memory"},
+		description: "memory limit of every container is set to a none zero value",
+		check: func(resources corev1.ResourceRequirements) string {
+			if resources.Limits.Memory().IsZero() {
+				return "memory limit is zero"
+			}
+			return ""
+		},
+	},
+	{
+		id:          "cpu-limit-nonzero",
+		severity:    SeverityWarning,
+		field:       []string{"limits", "cpu"},
+		description: "cpu limit of every container is set to a none zero value",
+		check: func(resources corev1.ResourceRequirements) string {
+			if resources.Limits.Cpu().IsZero() {
+				return "cpu limit is zero"
+			}
+			return ""
+		},
+	},
+	{
+		id:          "cpu-request-within-limit",
+		severity:    SeverityError,
+		field:       []string{"requests", "cpu"},
+		description: "cpu request of every container is less than or equal to its cpu limit",
+		check: func(resources corev1.ResourceRequirements) string {
+			if !resources.Limits.Cpu().IsZero() && resources.Requests.Cpu().Cmp(*resources.Limits.Cpu()) > 0 {
+				return "cpu request must be less than or equal to cpu limit"
+			}
+			return ""
+		},
+	},
+	{
+		id:          "memory-request-within-limit",
+		severity:    SeverityError,
+		field:       []string{"requests", "memory"},
+		description: "memory request of every container is less than or equal to its memory limit",
+		check: func(resources corev1.ResourceRequirements) string {
+			if !resources.Limits.Memory().IsZero() && resources.Requests.Memory().Cmp(*resources.Limits.Memory()) > 0 {
+				return "memory request must be less than or equal to memory limit"
+			}
+			return ""
+		},
+	},
+}
+
+// resourceRule checks the resources of every container of a workload.
+type resourceRule struct {
+	id          string
+	severity    Severity
+	description string
+	// field is the path of the checked resource below the resources of the container.
+	field []string
+	// check returns the message of the finding or an empty string if the resources are valid.
+	check func(resources corev1.ResourceRequirements) string
+}
+
+func (c *resourceRule) ID() string {
+	return c.id
+}
+
+func (c *resourceRule) Description() string {
+	return c.description
+}
+
+func (c *resourceRule) Severity() Severity {
+	return c.severity
+}
+
+func (c *resourceRule) Applies(obj *Object) bool {
+	return obj.Template != nil
+}
+
+func (c *resourceRule) Check(obj *Object) []Finding {
+	var findings []Finding
+	for _, container := range obj.Containers() {
+		if message := c.check(container.Resources); message != "" {
+			findings = append(findings, Finding{
+				ContainerType: container.Type,
+				Container:     container.Name,
+				Field:         container.Path.Child("resources", c.field...).String(),
+				Message:       message,
+			})
+		}
+	}
+	return findings
+}
+
+// Resources checks the requests and limits of a container with all resource rules and returns all findings.
+func Resources(resourceRequirements corev1.ResourceRequirements) []Finding {
+	var findings []Finding
+	for _, rule := range resourceRules {
+		if message := rule.check(resourceRequirements); message != "" {
+			findings = append(findings, Finding{Rule: rule.ID(), Severity: rule.Severity(), Message: message})
+		}
+	}
+	return findings
+}
+
+var maxLimitRules = []*maxLimitRule{
+	{
+		id:          "cpu-limit-max",
+		description: "cpu limit of every container does not exceed the threshold cores",
+		resource:    corev1.ResourceCPU,
+		threshold:   "cores",
+		max:         4,
+		quantity: func(cores float64) *resource.Quantity {
+			return resource.NewMilliQuantity(int64(cores*1000), resource.DecimalSI)
+		},
+	},
+	{
+		id:          "memory-limit-max",
+		description: "memory limit of every container does not exceed the threshold gibibytes",
+		resource:    corev1.ResourceMemory,
+		threshold:   "gibibytes",
+		max:         8,
+		quantity: func(gibibytes float64) *resource.Quantity {
+			return resource.NewQuantity(int64(gibibytes*(1<<30)), resource.BinarySI)
+		},
+	},
+}
+
+// maxLimitRule checks the limit of a resource of every container does not
+// exceed a maximum, which is configurable by a threshold.
+type maxLimitRule struct {
+	id          string
+	description string
+	resource    corev1.ResourceName
+	threshold   string
+	max         float64
+	// quantity converts the threshold to a resource quantity.
+	quantity func(float64) *resource.Quantity
+}
+
+func (m *maxLimitRule) ID() string {
+	return m.id
+}
+
+func (m *maxLimitRule) Description() string {
+	return m.description
+}
+
+func (m *maxLimitRule) Severity() Severity {
+	return SeverityWarning
+}
+
+func (m *maxLimitRule) Thresholds() []string {
+	return []string{m.threshold}
+}
+
+func (m *maxLimitRule) Applies(obj *Object) bool {
+	return obj.Template != nil
+}
+
+func (m *maxLimitRule) Check(obj *Object) []Finding {
+	max := obj.Threshold(m.id, m.threshold, m.max)
+	var findings []Finding
+	for _, container := range obj.Containers() {
+		limit, ok := container.Resources.Limits[m.resource]
+		if !ok || limit.Cmp(*m.quantity(max)) <= 0 {
+			continue
+		}
+		findings = append(findings, Finding{
+			ContainerType: container.Type,
+			Container:     container.Name,
+			Field:         container.Path.Child("resources", "limits", string(m.resource)).String(),
+			Message:       fmt.Sprintf("%s limit %s exceeds maximum of %v %s", m.resource, limit.String(), max, m.threshold),
+		})
+	}
+	return findings
+}
@@ -0,0 +1,226 @@
+package check
+
+import (
+	"fmt"
+)
+
+// Severity of a finding.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Valid returns true for the known severities.
+func (s Severity) Valid() bool {
+	return s == SeverityError || s == SeverityWarning || s == SeverityInfo
+}
+
+// AtLeast returns true if the severity is as severe as the given one or more severe.
+func (s Severity) AtLeast(other Severity) bool {
+	return s.level() >= other.level()
+}
+
+func (s Severity) level() int {
+	switch s {
+	case SeverityError:
+		return 3
+	case SeverityWarning:
+		return 2
+	case SeverityInfo:
+		return 1
+	}
+	return 0
+}
+
+// Rule checks a single aspect of kubernetes objects.
+type Rule interface {
+	// ID identifies the rule, e.g. in findings and on the command line.
+	ID() string
+	Description() string
+	// Severity is used for all findings of the rule which do not set a severity.
+	Severity() Severity
+	// Check returns all findings for the given object. It is called
+	// concurrently for different objects.
+	Check(obj *Object) []Finding
+}
+
+// ApplicableRule is implemented by rules, which only check some objects like
+// those of certain kinds. Rules without it apply to all objects.
+type ApplicableRule interface {
+	Rule
+	// Applies returns true if the rule checks the object.
+	Applies(obj *Object) bool
+}
+
+// ThresholdRule is implemented by rules with thresholds configured by name.
+type ThresholdRule interface {
+	Rule
+	// Thresholds returns the names of the thresholds of the rule.
+	Thresholds() []string
+}
+
+// GraphRule checks references between the objects checked in one run. Its
+// Check method returns no findings, CheckGraph is called for every object
+// once all objects of the run are known.
+type GraphRule interface {
+	Rule
+	CheckGraph(obj *Object, graph *Graph) []Finding
+}
+
+// Registry holds all known rules and whether they are enabled.
+type Registry struct {
+	rules    map[string]Rule
+	order    []string
+	disabled map[string]bool
+	// forced are the states set by Force, which take precedence over the configuration.
+	forced map[string]bool
+}
+
+// DefaultRegistry contains all built-in rules and is used by Path and Content.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		rules:    make(map[string]Rule),
+		disabled: make(map[string]bool),
+		forced:   make(map[string]bool),
+	}
+}
+
+// Register adds the rule to the default registry and panics if its ID is already taken.
+func Register(rule Rule) {
+	if err := DefaultRegistry.Register(rule); err != nil {
+		panic(err)
+	}
+}
+
+// Register adds the rule enabled to the registry.
+func (r *Registry) Register(rule Rule) error {
+	if _, ok := r.rules[rule.ID()]; ok {
+		return fmt.Errorf("rule %s already registered", rule.ID())
+	}
+	r.rules[rule.ID()] = rule
+	r.order = append(r.order, rule.ID())
+	return nil
+}
+
+// Rules returns all registered rules in the order of registration.
+func (r *Registry) Rules() []Rule {
+	var result []Rule
+	for _, id := range r.order {
+		result = append(result, r.rules[id])
+	}
+	return result
+}
+
+// Rule returns the rule with the given ID.
+func (r *Registry) Rule(id string) (Rule, bool) {
+	rule, ok := r.rules[id]
+	return rule, ok
+}
+
+// Enable enables the rule with the given ID.
+func (r *Registry) Enable(id string) error {
+	if _, ok := r.rules[id]; !ok {
+		return fmt.Errorf("rule %s not found", id)
+	}
+	delete(r.disabled, id)
+	return nil
+}
+
+// Disable disables the rule with the given ID.
+func (r *Registry) Disable(id string) error {
+	if _, ok := r.rules[id]; !ok {
+		return fmt.Errorf("rule %s not found", id)
+	}
+	r.disabled[id] = true
+	return nil
+}
+
+// Force enables or disables the rule with the given ID for all objects,
+// regardless of overrides in the configuration, like the command line does.
+func (r *Registry) Force(id string, enabled bool) error {
+	var err error
+	if enabled {
+		err = r.Enable(id)
+	} else {
+		err = r.Disable(id)
+	}
+	if err != nil {
+		return err
+	}
+	r.forced[id] = enabled
+	return nil
+}
+
+// Enabled returns true if the rule with the given ID is registered and enabled.
+func (r *Registry) Enabled(id string) bool {
+	_, ok := r.rules[id]
+	return ok && !r.disabled[id]
+}
+
+// Check runs all rules enabled for the object. Overrides of the object's
+// configuration take precedence over the registry. Rule, severity and the
+// object reference are filled in for every finding.
+func (r *Registry) Check(obj *Object) []Finding {
+	return r.run(obj, func(rule Rule) []Finding {
+		return rule.Check(obj)
+	})
+}
+
+// CheckGraph runs all graph rules enabled for the object like Check.
+func (r *Registry) CheckGraph(obj *Object, graph *Graph) []Finding {
+	return r.run(obj, func(rule Rule) []Finding {
+		if graphRule, ok := rule.(GraphRule); ok {
+			return graphRule.CheckGraph(obj, graph)
+		}
+		return nil
+	})
+}
+
+// Active returns true if the rule is enabled for the object and applies to
+// it. Overrides of the object's configuration take precedence over the
+// registry, states set by Force take precedence over both.
+func (r *Registry) Active(rule Rule, obj *Object) bool {
+	enabled := !r.disabled[rule.ID()]
+	if config := obj.config.Rule(rule.ID(), obj); config.Enabled != nil {
+		enabled = *config.Enabled
+	}
+	if forced, ok := r.forced[rule.ID()]; ok {
+		enabled = forced
+	}
+	if !enabled {
+		return false
+	}
+	if applicable, ok := rule.(ApplicableRule); ok {
+		return applicable.Applies(obj)
+	}
+	return true
+}
+
+func (r *Registry) run(obj *Object, check func(rule Rule) []Finding) []Finding {
+	var findings []Finding
+	for _, rule := range r.Rules() {
+		if !r.Active(rule, obj) {
+			continue
+		}
+		config := obj.config.Rule(rule.ID(), obj)
+		for _, finding := range check(rule) {
+			if finding.Rule == "" {
+				finding.Rule = rule.ID()
+			}
+			if config.Severity != "" {
+				finding.Severity = config.Severity
+			}
+			if finding.Severity == "" {
+				finding.Severity = rule.Severity()
+			}
+			findings = append(findings, obj.complete(finding))
+		}
+	}
+	return findings
+}
@@ -0,0 +1,122 @@
+package check_test
+
+import (
+	"github.com/seibert-media/k8s-manifest-check/check"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type testRule struct {
+	id       string
+	findings []check.Finding
+}
+
+func (t *testRule) ID() string {
+	return t.id
+}
+
+func (t *testRule) Description() string {
+	return "test rule"
+}
+
+func (t *testRule) Severity() check.Severity {
+	return check.SeverityWarning
+}
+
+func (t *testRule) Check(obj *check.Object) []check.Finding {
+	return t.findings
+}
+
+// secretRule is a test rule, which applies to Secrets only.
+type secretRule struct {
+	testRule
+}
+
+func (s *secretRule) Applies(obj *check.Object) bool {
+	return obj.Kind == "Secret"
+}
+
+// checkerWithRules returns a checker with only the given rules of the default
+// registry, all of them enabled.
+func checkerWithRules(ids ...string) *check.Checker {
+	checker := check.New()
+	checker.Registry = check.NewRegistry()
+	for _, id := range ids {
+		rule, ok := check.DefaultRegistry.Rule(id)
+		Expect(ok).To(BeTrue(), id)
+		Expect(checker.Registry.Register(rule)).To(BeNil())
+	}
+	return checker
+}
+
+// checkFindings checks the manifests as one run including the references
+// between them and returns the findings as strings.
+func checkFindings(checker *check.Checker, file string, manifests ...string) []string {
+	report := &check.Report{}
+	for _, manifest := range manifests {
+		checker.Content(report, file, []byte(manifest))
+	}
+	checker.References(report)
+	var result []string
+	for _, finding := range report.Findings {
+		result = append(result, finding.String())
+	}
+	return result
+}
+
+var _ = Describe("Registry", func() {
+	var registry *check.Registry
+	var obj *check.Object
+	BeforeEach(func() {
+		registry = check.NewRegistry()
+		obj = &check.Object{File: "pod.yaml", Document: 2, Kind: "Pod", Namespace: "default", Name: "hello-world"}
+	})
+	It("return rules in order of registration", func() {
+		Expect(registry.Register(&testRule{id: "b"})).To(BeNil())
+		Expect(registry.Register(&testRule{id: "a"})).To(BeNil())
+		rules := registry.Rules()
+		Expect(rules).To(HaveLen(2))
+		Expect(rules[0].ID()).To(Equal("b"))
+		Expect(rules[1].ID()).To(Equal("a"))
+	})
+	It("return error if rule is already registered", func() {
+		Expect(registry.Register(&testRule{id: "a"})).To(BeNil())
+		Expect(registry.Register(&testRule{id: "a"})).NotTo(BeNil())
+	})
+	It("return error if unknown rule is enabled or disabled", func() {
+		Expect(registry.Enable("unknown")).NotTo(BeNil())
+		Expect(registry.Disable("unknown")).NotTo(BeNil())
+	})
+	It("complete findings of rules", func() {
+		registry.Register(&testRule{id: "a", findings: []check.Finding{{Message: "broken"}}})
+		findings := registry.Check(obj)
+		Expect(findings).To(HaveLen(1))
+		Expect(findings[0].Rule).To(Equal("a"))
+		Expect(findings[0].Severity).To(Equal(check.SeverityWarning))
+		Expect(findings[0].String()).To(Equal("broken in pod.yaml (document 2, Pod default/hello-world) [a]"))
+	})
+	It("skip disabled rules", func() {
+		registry.Register(&testRule{id: "a", findings: []check.Finding{{Message: "broken"}}})
+		Expect(registry.Enabled("a")).To(BeTrue())
+		Expect(registry.Disable("a")).To(BeNil())
+		Expect(registry.Enabled("a")).To(BeFalse())
+		Expect(registry.Check(obj)).To(BeEmpty())
+		Expect(registry.Enable("a")).To(BeNil())
+		Expect(registry.Check(obj)).To(HaveLen(1))
+	})
+	It("skip rules not applying to the object", func() {
+		rule := &secretRule{testRule{id: "a", findings: []check.Finding{{Message: "broken"}}}}
+		registry.Register(rule)
+		Expect(registry.Active(rule, obj)).To(BeFalse())
+		Expect(registry.Check(obj)).To(BeEmpty())
+		obj.Kind = "Secret"
+		Expect(registry.Active(rule, obj)).To(BeTrue())
+		Expect(registry.Check(obj)).To(HaveLen(1))
+	})
+	It("contain the resource rules by default", func() {
+		for _, id := range []string{"cpu-request-nonzero", "memory-request-nonzero", "cpu-limit-nonzero", "memory-limit-nonzero", "cpu-request-within-limit", "memory-request-within-limit"} {
+			_, ok := check.DefaultRegistry.Rule(id)
+			Expect(ok).To(BeTrue(), id)
+		}
+	})
+})
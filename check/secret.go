@@ -0,0 +1,280 @@
+package check
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Rule IDs of the Secret checks.
+const (
+	SecretCredentialRule = "secret-plaintext-credential"
+	SecretEncryptionRule = "secret-not-encrypted"
+)
+
+func init() {
+	Register(&secretCredentialRule{})
+	Register(&secretEncryptionRule{})
+	DefaultRegistry.Disable(SecretEncryptionRule)
+}
+
+// Secret encryption formats of the configuration. Values in these formats
+// are encrypted or references resolved at deploy time.
+const (
+	SecretEncryptionSOPS        = "sops"
+	SecretEncryptionVaultPlugin = "argocd-vault-plugin"
+	SecretEncryptionBankVaults  = "bank-vaults"
+	// SecretEncryptionSealedSecrets are the values of SealedSecrets of
+	// bitnami.com, which are encrypted for the controller in the cluster.
+	SecretEncryptionSealedSecrets = "sealed-secrets"
+	// SecretEncryptionNone accepts plaintext values.
+	SecretEncryptionNone = "none"
+)
+
+// secretEncryptions are all known encryption formats.
+var secretEncryptions = []string{SecretEncryptionSOPS, SecretEncryptionVaultPlugin, SecretEncryptionBankVaults, SecretEncryptionSealedSecrets}
+
+var secretEncryptionFormats = map[string]*regexp.Regexp{
+	SecretEncryptionSOPS:        regexp.MustCompile(`^ENC\[[A-Z0-9_]+,data:.*\]$`),
+	SecretEncryptionVaultPlugin: regexp.MustCompile(`^<path:[^>#]+#[^>]+>$`),
+	SecretEncryptionBankVaults:  regexp.MustCompile(`^vault:[^#\s]+#\S+$`),
+}
+
+// secretPatterns detect well known credentials by their format.
+var secretPatterns = []struct {
+	description string
+	pattern     *regexp.Regexp
+}{
+	{"a private key", regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )*PRIVATE KEY-----`)},
+	{"an AWS access key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"a GitHub token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"a GitLab token", regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`)},
+	{"a Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"a Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`)},
+	{"a Stripe key", regexp.MustCompile(`\b[sr]k_live_[0-9A-Za-z]{24,}`)},
+	{"a JSON web token", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)},
+	{"a URL with password", regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^/\s:@]+:[^/\s@]+@`)},
+}
+
+// secretValue is a value of the data or stringData of a Secret. Values of
+// data are base64 decoded if possible.
+type secretValue struct {
+	path  *field.Path
+	field string
+	key   string
+	// raw is the value as written in the document.
+	raw   string
+	value string
+	// sealed is true for the encrypted data of a SealedSecret.
+	sealed bool
+}
+
+// isSecret returns true for Secrets and SealedSecrets of bitnami.com.
+func isSecret(obj *Object) bool {
+	return obj.Kind == "Secret" || isSealedSecret(obj)
+}
+
+func isSealedSecret(obj *Object) bool {
+	return obj.Kind == "SealedSecret" && strings.HasPrefix(obj.APIVersion, "bitnami.com/")
+}
+
+// secretValues returns all values of the Secret or SealedSecret in the order
+// of their keys or nil for other objects. The Secret is read from the
+// document, because encrypted values are not valid base64 and fail to decode
+// into the API type.
+func secretValues(obj *Object) []secretValue {
+	if !isSecret(obj) {
+		return nil
+	}
+	raw, err := obj.raw()
+	if err != nil {
+		return nil
+	}
+	document, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if isSealedSecret(obj) {
+		return sealedSecretValues(document)
+	}
+	var result []secretValue
+	for _, name := range []string{"data", "stringData"} {
+		values, ok := document[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, key := range sortedKeys(values) {
+			s, ok := values[key].(string)
+			if !ok || s == "" {
+				continue
+			}
+			value := secretValue{path: field.NewPath(name, key), field: name, key: key, raw: s, value: s}
+			if name == "data" {
+				if decoded, err := base64.StdEncoding.DecodeString(s); err == nil {
+					value.value = string(decoded)
+				}
+			}
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// sealedSecretValues returns the values of spec.encryptedData of the
+// SealedSecret document in the order of their keys.
+func sealedSecretValues(document map[string]interface{}) []secretValue {
+	spec, ok := document["spec"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	values, ok := spec["encryptedData"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	var result []secretValue
+	for _, key := range sortedKeys(values) {
+		s, ok := values[key].(string)
+		if !ok || s == "" {
+			continue
+		}
+		path := field.NewPath("spec", "encryptedData", key)
+		result = append(result, secretValue{path: path, field: "encryptedData", key: key, raw: s, value: s, sealed: true})
+	}
+	return result
+}
+
+// encryption returns the format the value is encrypted with.
+func (v secretValue) encryption() (string, bool) {
+	if v.sealed {
+		return SecretEncryptionSealedSecrets, true
+	}
+	for _, format := range []string{SecretEncryptionSOPS, SecretEncryptionVaultPlugin, SecretEncryptionBankVaults} {
+		pattern := secretEncryptionFormats[format]
+		if pattern.MatchString(v.raw) || pattern.MatchString(strings.TrimSpace(v.value)) {
+			return format, true
+		}
+	}
+	return "", false
+}
+
+// secretCredentialRule reports values of Secrets, which look like real
+// credentials committed in plaintext. Findings never contain the value.
+type secretCredentialRule struct{}
+
+func (s *secretCredentialRule) ID() string {
+	return SecretCredentialRule
+}
+
+func (s *secretCredentialRule) Description() string {
+	return "Secrets contain no plaintext credentials like tokens, private keys or high entropy strings"
+}
+
+func (s *secretCredentialRule) Severity() Severity {
+	return SeverityError
+}
+
+func (s *secretCredentialRule) Thresholds() []string {
+	return []string{"entropy", "length"}
+}
+
+func (s *secretCredentialRule) Applies(obj *Object) bool {
+	return isSecret(obj)
+}
+
+func (s *secretCredentialRule) Check(obj *Object) []Finding {
+	minEntropy := obj.Threshold(SecretCredentialRule, "entropy", 3.5)
+	minLength := obj.Threshold(SecretCredentialRule, "length", 16)
+	var findings []Finding
+	for _, value := range secretValues(obj) {
+		if _, ok := value.encryption(); ok {
+			continue
+		}
+		if description, ok := credential(value.value, minEntropy, int(minLength)); ok {
+			findings = append(findings, Finding{
+				Field:   value.path.String(),
+				Message: fmt.Sprintf("%s key %q contains %s", value.field, value.key, description),
+			})
+		}
+	}
+	return findings
+}
+
+// credential returns the description of the credential the value looks
+// like. Values without a known format are credentials if they are a single
+// word of at least minLength characters with an entropy of at least
+// minEntropy bits per character.
+func credential(value string, minEntropy float64, minLength int) (string, bool) {
+	for _, pattern := range secretPatterns {
+		if pattern.pattern.MatchString(value) {
+			return pattern.description, true
+		}
+	}
+	value = strings.TrimSpace(value)
+	if len(value) < minLength || strings.ContainsAny(value, " \t\r\n") || strings.HasPrefix(value, "-----BEGIN ") {
+		return "", false
+	}
+	if entropy(value) >= minEntropy {
+		return "a high entropy string", true
+	}
+	return "", false
+}
+
+// entropy returns the Shannon entropy of the value in bits per character.
+func entropy(value string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range value {
+		counts[r]++
+		total++
+	}
+	result := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		result -= p * math.Log2(p)
+	}
+	return result
+}
+
+// secretEncryptionRule reports values of Secrets, which are not encrypted in
+// one of the formats accepted by the configuration.
+type secretEncryptionRule struct{}
+
+func (s *secretEncryptionRule) ID() string {
+	return SecretEncryptionRule
+}
+
+func (s *secretEncryptionRule) Description() string {
+	return "values of Secrets are encrypted in a format accepted by the configuration"
+}
+
+func (s *secretEncryptionRule) Severity() Severity {
+	return SeverityError
+}
+
+func (s *secretEncryptionRule) Applies(obj *Object) bool {
+	return isSecret(obj)
+}
+
+func (s *secretEncryptionRule) Check(obj *Object) []Finding {
+	accepted := obj.config.SecretEncryption()
+	if contains(accepted, SecretEncryptionNone) {
+		return nil
+	}
+	var findings []Finding
+	for _, value := range secretValues(obj) {
+		format, ok := value.encryption()
+		if ok && contains(accepted, format) {
+			continue
+		}
+		message := fmt.Sprintf("%s key %q is not encrypted", value.field, value.key)
+		if ok {
+			message = fmt.Sprintf("%s key %q is encrypted by %s, which is not accepted", value.field, value.key, format)
+		}
+		findings = append(findings, Finding{Field: value.path.String(), Message: message})
+	}
+	return findings
+}
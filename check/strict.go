@@ -0,0 +1,282 @@
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// UnknownFieldRule is the ID of the rule enabled by strict mode.
+const UnknownFieldRule = "unknown-field"
+
+func init() {
+	Register(&unknownFieldRule{})
+	DefaultRegistry.Disable(UnknownFieldRule)
+}
+
+// unknownFieldRule reports fields of the document which do not exist in the
+// API type of the object and are dropped silently when decoding.
+type unknownFieldRule struct{}
+
+func (u *unknownFieldRule) ID() string {
+	return UnknownFieldRule
+}
+
+func (u *unknownFieldRule) Description() string {
+	return "every field of the manifest exists in the API type of the object"
+}
+
+func (u *unknownFieldRule) Severity() Severity {
+	return SeverityError
+}
+
+// Check compares the document with the schema of its kind if one is loaded,
+// because it knows the fields of newer Kubernetes releases. Otherwise it is
+// compared with the vendored API type.
+func (u *unknownFieldRule) Check(obj *Object) []Finding {
+	raw, err := obj.raw()
+	if err != nil {
+		return nil
+	}
+	var unknownFields []UnknownField
+	switch {
+	case obj.schemas.Has(obj.APIVersion, obj.Kind):
+		unknownFields = obj.schemas.UnknownFields(obj.APIVersion, obj.Kind, raw)
+	case obj.Runtime != nil:
+		unknownFields = UnknownFields(raw, reflect.TypeOf(obj.Runtime), nil)
+	}
+	var findings []Finding
+	for _, unknown := range unknownFields {
+		message := fmt.Sprintf("unknown field %q", unknown.Name)
+		if unknown.Suggestion != "" {
+			message = fmt.Sprintf("%s, did you mean %q?", message, unknown.Suggestion)
+		}
+		findings = append(findings, Finding{Field: unknown.Path.String(), Message: message})
+	}
+	return findings
+}
+
+// UnknownField is a field of a decoded document missing in the Go type.
+type UnknownField struct {
+	Path *field.Path
+	Name string
+	// Suggestion is the most similar known field or empty if no field is similar.
+	Suggestion string
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// newerFields are the JSON names of the fields added to the API types after
+// the vendored k8s.io/api, up to Kubernetes 1.34. They are not unknown, but
+// their values are not inspected.
+var newerFields = map[reflect.Type][]string{
+	reflect.TypeOf(corev1.CSIPersistentVolumeSource{}): {"controllerExpandSecretRef", "nodeExpandSecretRef"},
+	reflect.TypeOf(corev1.CinderVolumeSource{}):        {"secretRef"},
+	reflect.TypeOf(corev1.ConfigMap{}):                 {"immutable"},
+	reflect.TypeOf(corev1.Container{}):                 {"resizePolicy", "restartPolicy", "restartPolicyRules", "startupProbe"},
+	reflect.TypeOf(corev1.ContainerStatus{}):           {"allocatedResources", "allocatedResourcesStatus", "resources", "started", "stopSignal", "user", "volumeMounts"},
+	reflect.TypeOf(corev1.EndpointPort{}):              {"appProtocol"},
+	reflect.TypeOf(corev1.EnvVarSource{}):              {"fileKeyRef"},
+	reflect.TypeOf(corev1.Lifecycle{}):                 {"stopSignal"},
+	reflect.TypeOf(corev1.LoadBalancerIngress{}):       {"ipMode", "ports"},
+	reflect.TypeOf(corev1.LocalVolumeSource{}):         {"fsType"},
+	reflect.TypeOf(corev1.NamespaceStatus{}):           {"conditions"},
+	reflect.TypeOf(corev1.NodeSpec{}):                  {"podCIDRs"},
+	reflect.TypeOf(corev1.PersistentVolumeClaimSpec{}): {"dataSource", "dataSourceRef", "volumeAttributesClassName"},
+	reflect.TypeOf(corev1.PersistentVolumeClaimStatus{}): {"allocatedResourceStatuses", "allocatedResources", "currentVolumeAttributesClassName",
+		"modifyVolumeStatus"},
+	reflect.TypeOf(corev1.PersistentVolumeSpec{}):   {"volumeAttributesClassName"},
+	reflect.TypeOf(corev1.PersistentVolumeStatus{}): {"lastPhaseTransitionTime"},
+	reflect.TypeOf(corev1.PodAffinityTerm{}):        {"matchLabelKeys", "mismatchLabelKeys", "namespaceSelector"},
+	reflect.TypeOf(corev1.PodCondition{}):           {"observedGeneration"},
+	reflect.TypeOf(corev1.PodSecurityContext{}): {"appArmorProfile", "fsGroupChangePolicy", "seLinuxChangePolicy", "seccompProfile",
+		"supplementalGroupsPolicy", "sysctls", "windowsOptions"},
+	reflect.TypeOf(corev1.PodSpec{}): {"enableServiceLinks", "ephemeralContainers", "hostUsers", "hostnameOverride", "os", "overhead",
+		"preemptionPolicy", "readinessGates", "resourceClaims", "resources", "runtimeClassName", "schedulingGates", "setHostnameAsFQDN",
+		"topologySpreadConstraints"},
+	reflect.TypeOf(corev1.PodStatus{}): {"ephemeralContainerStatuses", "extendedResourceClaimStatus", "hostIPs", "observedGeneration",
+		"podIPs", "resize", "resourceClaimStatuses"},
+	reflect.TypeOf(corev1.Probe{}):                {"grpc", "terminationGracePeriodSeconds"},
+	reflect.TypeOf(corev1.QuobyteVolumeSource{}):  {"tenant"},
+	reflect.TypeOf(corev1.ResourceQuotaSpec{}):    {"scopeSelector"},
+	reflect.TypeOf(corev1.ResourceRequirements{}): {"claims"},
+	reflect.TypeOf(corev1.Secret{}):               {"immutable"},
+	reflect.TypeOf(corev1.SecurityContext{}):      {"appArmorProfile", "procMount", "seccompProfile", "windowsOptions"},
+	reflect.TypeOf(corev1.ServicePort{}):          {"appProtocol"},
+	reflect.TypeOf(corev1.ServiceSpec{}): {"allocateLoadBalancerNodePorts", "clusterIPs", "internalTrafficPolicy", "ipFamilies", "ipFamilyPolicy",
+		"loadBalancerClass", "trafficDistribution"},
+	reflect.TypeOf(corev1.ServiceStatus{}):                    {"conditions"},
+	reflect.TypeOf(corev1.VolumeMount{}):                      {"recursiveReadOnly", "subPathExpr"},
+	reflect.TypeOf(corev1.VolumeProjection{}):                 {"clusterTrustBundle", "podCertificate", "serviceAccountToken"},
+	reflect.TypeOf(corev1.VolumeSource{}):                     {"csi", "ephemeral", "image"},
+	reflect.TypeOf(appsv1.DeploymentStatus{}):                 {"terminatingReplicas"},
+	reflect.TypeOf(appsv1.ReplicaSetStatus{}):                 {"terminatingReplicas"},
+	reflect.TypeOf(appsv1.RollingUpdateDaemonSet{}):           {"maxSurge"},
+	reflect.TypeOf(appsv1.RollingUpdateStatefulSetStrategy{}): {"maxUnavailable"},
+	reflect.TypeOf(appsv1.StatefulSetSpec{}):                  {"minReadySeconds", "ordinals", "persistentVolumeClaimRetentionPolicy"},
+	reflect.TypeOf(appsv1.StatefulSetStatus{}):                {"availableReplicas"},
+	reflect.TypeOf(batchv1.JobSpec{}): {"backoffLimitPerIndex", "completionMode", "managedBy", "maxFailedIndexes", "podFailurePolicy",
+		"podReplacementPolicy", "successPolicy", "suspend", "ttlSecondsAfterFinished"},
+	reflect.TypeOf(batchv1.JobStatus{}):                       {"completedIndexes", "failedIndexes", "ready", "terminating", "uncountedTerminatedPods"},
+	reflect.TypeOf(policyv1beta1.PodDisruptionBudgetSpec{}):   {"unhealthyPodEvictionPolicy"},
+	reflect.TypeOf(policyv1beta1.PodDisruptionBudgetStatus{}): {"conditions"},
+	reflect.TypeOf(networkingv1.NetworkPolicyPort{}):          {"endPort"},
+}
+
+// UnknownFields returns all fields of the generic JSON value, which do not
+// exist in the Go type t by their JSON names. Fields added to the type by
+// newer Kubernetes releases are known. Types with custom JSON decoding are
+// not inspected.
+func UnknownFields(value interface{}, t reflect.Type, path *field.Path) []UnknownField {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if reflect.PtrTo(t).Implements(unmarshalerType) {
+		return nil
+	}
+	var result []UnknownField
+	switch t.Kind() {
+	case reflect.Struct:
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		fields := jsonFields(t)
+		names := newerFields[t]
+		for name := range fields {
+			names = append(names, name)
+		}
+		for _, key := range sortedKeys(values) {
+			fieldType, ok := fields[key]
+			if !ok {
+				if !contains(newerFields[t], key) {
+					result = append(result, UnknownField{Path: path.Child(key), Name: key, Suggestion: suggest(key, names)})
+				}
+				continue
+			}
+			result = append(result, UnknownFields(values[key], fieldType, path.Child(key))...)
+		}
+	case reflect.Map:
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		for _, key := range sortedKeys(values) {
+			result = append(result, UnknownFields(values[key], t.Elem(), path.Child(key))...)
+		}
+	case reflect.Slice, reflect.Array:
+		values, ok := value.([]interface{})
+		if !ok {
+			return nil
+		}
+		for i, v := range values {
+			result = append(result, UnknownFields(v, t.Elem(), path.Index(i))...)
+		}
+	}
+	return result
+}
+
+// jsonFields returns the types of the fields of the struct by their JSON
+// names. Fields of embedded and inline structs are included.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	result := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		inline := strings.Contains(tag, ",inline") || (f.Anonymous && name == "")
+		if inline {
+			embedded := f.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for name, fieldType := range jsonFields(embedded) {
+					result[name] = fieldType
+				}
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		result[name] = f.Type
+	}
+	return result
+}
+
+// suggest returns the known field most similar to the unknown name or an
+// empty string if no field is similar enough.
+func suggest(name string, candidates []string) string {
+	maxDistance := len(name)/3 + 1
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+	candidates = append([]string(nil), candidates...)
+	sort.Strings(candidates)
+	best := ""
+	for _, candidate := range candidates {
+		if distance := levenshtein(strings.ToLower(name), strings.ToLower(candidate)); distance <= maxDistance {
+			best = candidate
+			maxDistance = distance - 1
+		}
+	}
+	return best
+}
+
+// levenshtein returns the edit distance of both strings.
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// sortedKeys returns the keys of the map in ascending order.
+func sortedKeys(values map[string]interface{}) []string {
+	var keys []string
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
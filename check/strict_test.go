@@ -0,0 +1,141 @@
+package check_test
+
+import (
+	"github.com/seibert-media/k8s-manifest-check/check"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Strict", func() {
+	var checker *check.Checker
+	BeforeEach(func() {
+		checker = check.New()
+		checker.Registry = check.NewRegistry()
+		for _, rule := range check.DefaultRegistry.Rules() {
+			checker.Registry.Register(rule)
+		}
+		for _, rule := range checker.Registry.Rules() {
+			if rule.ID() != check.UnknownFieldRule {
+				checker.Registry.Disable(rule.ID())
+			}
+		}
+	})
+	It("be disabled by default", func() {
+		Expect(check.DefaultRegistry.Enabled(check.UnknownFieldRule)).To(BeFalse())
+	})
+	It("report nothing for known fields", func() {
+		report := &check.Report{}
+		checker.Content(report, "pod.yaml", []byte(validPod))
+		Expect(report.Findings).To(BeEmpty())
+	})
+	It("report misspelled fields with suggestion and position", func() {
+		report := &check.Report{}
+		checker.Content(report, "deploy.yaml", []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  lables:
+    app: web
+spec:
+  template:
+    metadata:
+      annotations:
+        any-key: any-value
+    spec:
+      containers:
+      - name: app
+        image: "ubuntu:14.04"
+        resouces:
+          limits:
+            cpu: 100m
+        ports:
+        - containerPort: 80
+          protocl: TCP
+      unrelated: true
+`))
+		var findings []string
+		for _, finding := range report.Findings {
+			findings = append(findings, finding.String())
+		}
+		Expect(findings).To(Equal([]string{
+			`unknown field "lables", did you mean "labels"? in deploy.yaml:5:3 (document 1, Deployment web) [unknown-field]`,
+			`unknown field "protocl", did you mean "protocol"? in deploy.yaml:21:11 (document 1, Deployment web) [unknown-field]`,
+			`unknown field "resouces", did you mean "resources"? in deploy.yaml:16:9 (document 1, Deployment web) [unknown-field]`,
+			`unknown field "unrelated" in deploy.yaml:22:7 (document 1, Deployment web) [unknown-field]`,
+		}))
+		Expect(report.Findings[2].Field).To(Equal("spec.template.spec.containers[0].resouces"))
+	})
+	It("accept fields added by newer kubernetes releases", func() {
+		checker := checkerWithRules(check.UnknownFieldRule, "pod-security-restricted")
+		Expect(checkFindings(checker, "pod.yaml", restrictedPod+`    startupProbe:
+      httpGet:
+        path: /healthz
+        port: 8080
+`)).To(BeEmpty())
+	})
+	It("check batch/v1 cron jobs only against the schema", func() {
+		cronJob := `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: nightly
+spec:
+  schedule: "0 3 * * *"
+  timeZone: Europe/Berlin
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          restartPolicy: Never
+          containers:
+          - name: app
+            image: "ubuntu:14.04"
+            comand: ["backup"]
+`
+		report := &check.Report{}
+		checker.Content(report, "cronjob.yaml", []byte(cronJob))
+		Expect(report.Findings).To(BeEmpty())
+		var err error
+		checker.Schemas, err = check.LoadSchemas("../openapi/v1.26")
+		Expect(err).To(BeNil())
+		report = &check.Report{}
+		checker.Content(report, "cronjob.yaml", []byte(cronJob))
+		var findings []string
+		for _, finding := range report.Findings {
+			findings = append(findings, finding.String())
+		}
+		Expect(findings).To(Equal([]string{
+			`unknown field "comand", did you mean "command"? in cronjob.yaml:16:13 (document 1, CronJob nightly) [unknown-field]`,
+		}))
+	})
+	It("check fields against the schema if one is loaded", func() {
+		var err error
+		checker.Schemas, err = check.LoadSchemas("../openapi/v1.26")
+		Expect(err).To(BeNil())
+		report := &check.Report{}
+		checker.Content(report, "pod.yaml", []byte(`apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+  annotations:
+    any-key: any-value
+spec:
+  hostUsers: false
+  securityContext:
+    seccompProfil:
+      type: RuntimeDefault
+  containers:
+  - name: web
+    image: web:1.0
+    resources:
+      limits:
+        cpu: 100m
+`))
+		var findings []string
+		for _, finding := range report.Findings {
+			findings = append(findings, finding.String())
+		}
+		Expect(findings).To(Equal([]string{
+			`unknown field "seccompProfil", did you mean "seccompProfile"? in pod.yaml:10:5 (document 1, Pod web) [unknown-field]`,
+		}))
+	})
+})
@@ -0,0 +1,57 @@
+package check
+
+import (
+	"strings"
+)
+
+const (
+	// IgnoreAnnotation lists the comma separated IDs of rules whose findings
+	// are suppressed for the annotated object. The annotation
+	// "<IgnoreAnnotation>.<container>" suppresses findings of a single container.
+	IgnoreAnnotation = "k8s-manifest-check.seibert-media.net/ignore"
+	// IgnoreReasonAnnotation justifies the suppression. The annotation
+	// "<IgnoreReasonAnnotation>.<container>" justifies the suppression of a single container.
+	IgnoreReasonAnnotation = "k8s-manifest-check.seibert-media.net/ignore-reason"
+)
+
+// Suppression returns the justification if the finding is suppressed by an
+// annotation of the object or of its pod template.
+func (o *Object) Suppression(finding Finding) (string, bool) {
+	annotations := o.annotations()
+	if finding.Container != "" {
+		if ignores(annotations[IgnoreAnnotation+"."+finding.Container], finding.Rule) {
+			if reason, ok := annotations[IgnoreReasonAnnotation+"."+finding.Container]; ok {
+				return reason, true
+			}
+			return annotations[IgnoreReasonAnnotation], true
+		}
+	}
+	if ignores(annotations[IgnoreAnnotation], finding.Rule) {
+		return annotations[IgnoreReasonAnnotation], true
+	}
+	return "", false
+}
+
+// annotations returns the annotations of the object merged with those of its
+// pod template, the annotations of the object take precedence.
+func (o *Object) annotations() map[string]string {
+	result := make(map[string]string)
+	if o.Template != nil {
+		for key, value := range o.Template.Annotations {
+			result[key] = value
+		}
+	}
+	for key, value := range o.Annotations {
+		result[key] = value
+	}
+	return result
+}
+
+func ignores(value string, rule string) bool {
+	for _, id := range strings.Split(value, ",") {
+		if strings.TrimSpace(id) == rule {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package check_test
+
+import (
+	"github.com/seibert-media/k8s-manifest-check/check"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Suppression", func() {
+	It("suppress findings of rules listed in the object annotation", func() {
+		report := &check.Report{}
+		check.Content(report, "job.yaml", []byte(`apiVersion: batch/v1
+kind: Job
+metadata:
+  name: nightly
+  annotations:
+    k8s-manifest-check.seibert-media.net/ignore: cpu-limit-nonzero, memory-limit-nonzero
+    k8s-manifest-check.seibert-media.net/ignore-reason: nightly batch may use idle resources
+spec:
+  template:
+    spec:
+      containers:
+      - name: worker
+        image: "ubuntu:14.04"
+        resources:
+          requests:
+            cpu: 100m
+            memory: 100Mi
+`))
+		Expect(report.Findings).To(BeEmpty())
+		Expect(report.Valid()).To(BeTrue())
+		Expect(report.Suppressed).To(HaveLen(2))
+		Expect(report.Suppressed[0].Rule).To(Equal("memory-limit-nonzero"))
+		Expect(report.Suppressed[0].Justification).To(Equal("nightly batch may use idle resources"))
+		Expect(report.Suppressed[1].Rule).To(Equal("cpu-limit-nonzero"))
+	})
+	It("suppress findings of a single container by the pod template annotation", func() {
+		report := &check.Report{}
+		check.Content(report, "deploy.yaml", []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    metadata:
+      annotations:
+        k8s-manifest-check.seibert-media.net/ignore.sidecar: cpu-limit-nonzero
+        k8s-manifest-check.seibert-media.net/ignore-reason.sidecar: sidecar is throttled by the proxy
+    spec:
+      containers:
+      - name: app
+        image: "ubuntu:14.04"
+        resources:
+          limits:
+            memory: 100Mi
+          requests:
+            cpu: 100m
+            memory: 100Mi
+      - name: sidecar
+        image: "ubuntu:14.04"
+        resources:
+          limits:
+            memory: 100Mi
+          requests:
+            cpu: 100m
+            memory: 100Mi
+`))
+		Expect(report.Findings).To(HaveLen(1))
+		Expect(report.Findings[0].Container).To(Equal("app"))
+		Expect(report.Suppressed).To(HaveLen(1))
+		Expect(report.Suppressed[0].Container).To(Equal("sidecar"))
+		Expect(report.Suppressed[0].Justification).To(Equal("sidecar is throttled by the proxy"))
+	})
+	It("keep findings of rules not listed", func() {
+		obj := &check.Object{Annotations: map[string]string{check.IgnoreAnnotation: "cpu-limit-nonzero"}}
+		_, ok := obj.Suppression(check.Finding{Rule: "cpu-limit-max"})
+		Expect(ok).To(BeFalse())
+		_, ok = obj.Suppression(check.Finding{Rule: "cpu-limit-nonzero", Container: "app"})
+		Expect(ok).To(BeTrue())
+	})
+})
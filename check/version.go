@@ -0,0 +1,39 @@
+package check
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// KubernetesVersion is the major and minor version of a Kubernetes release.
+type KubernetesVersion struct {
+	Major int
+	Minor int
+}
+
+var kubernetesVersionRegexp = regexp.MustCompile(`^v?(\d+)\.(\d+)(\.\d+)?(-[0-9A-Za-z.-]+)?$`)
+
+// ParseKubernetesVersion parses versions like "1.22", "v1.22" or "v1.22.3".
+// The patch version and pre-release suffix are ignored.
+func ParseKubernetesVersion(value string) (KubernetesVersion, error) {
+	match := kubernetesVersionRegexp.FindStringSubmatch(value)
+	if match == nil {
+		return KubernetesVersion{}, fmt.Errorf("kubernetes version %s is invalid", value)
+	}
+	major, _ := strconv.Atoi(match[1])
+	minor, _ := strconv.Atoi(match[2])
+	return KubernetesVersion{Major: major, Minor: minor}, nil
+}
+
+// AtLeast returns true if the version is equal to or newer than the other one.
+func (v KubernetesVersion) AtLeast(other KubernetesVersion) bool {
+	if v.Major != other.Major {
+		return v.Major > other.Major
+	}
+	return v.Minor >= other.Minor
+}
+
+func (v KubernetesVersion) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
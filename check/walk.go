@@ -0,0 +1,164 @@
+package check
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFile contains glob patterns, one per line, of files and directories
+// to skip while walking the directory containing it.
+const IgnoreFile = ".k8s-manifest-check-ignore"
+
+var manifestExtensions = []string{".yaml", ".yml", ".json"}
+
+// ignorePatterns are the patterns of an ignore file relative to its directory.
+type ignorePatterns struct {
+	dir      string
+	patterns []string
+}
+
+// Files returns all manifest files below the directory root in lexical order.
+// Hidden directories, excluded paths and paths listed in ignore files are
+// skipped. If include patterns are set only matching files are returned.
+func (c *Checker) Files(root string) ([]string, error) {
+	var files []string
+	var ignores []ignorePatterns
+	err := filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if info.IsDir() {
+			if rel != "." && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if rel != "." && (matchAny(c.Exclude, rel, true) || ignored(ignores, rel, true)) {
+				return filepath.SkipDir
+			}
+			patterns, err := readIgnoreFile(filepath.Join(file, IgnoreFile))
+			if err != nil {
+				return err
+			}
+			if len(patterns) > 0 {
+				ignores = append(ignores, ignorePatterns{dir: rel, patterns: patterns})
+			}
+			return nil
+		}
+		if !isManifest(info.Name()) {
+			return nil
+		}
+		if matchAny(c.Exclude, rel, false) || ignored(ignores, rel, false) {
+			return nil
+		}
+		if len(c.Include) > 0 && !matchAny(c.Include, rel, false) {
+			return nil
+		}
+		files = append(files, file)
+		return nil
+	})
+	return files, err
+}
+
+func isManifest(name string) bool {
+	for _, extension := range manifestExtensions {
+		if strings.HasSuffix(strings.ToLower(name), extension) {
+			return true
+		}
+	}
+	return false
+}
+
+// readIgnoreFile returns the patterns of the ignore file, comments and empty lines are skipped.
+// A missing ignore file contains no patterns.
+func readIgnoreFile(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, scanner.Err()
+}
+
+// ignored returns true if the path relative to the walked root is matched by an ignore file above it.
+func ignored(ignores []ignorePatterns, rel string, dir bool) bool {
+	for _, ignore := range ignores {
+		sub := rel
+		if ignore.dir != "." {
+			if !strings.HasPrefix(rel, ignore.dir+"/") {
+				continue
+			}
+			sub = strings.TrimPrefix(rel, ignore.dir+"/")
+		}
+		if matchAny(ignore.patterns, sub, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(patterns []string, rel string, dir bool) bool {
+	for _, pattern := range patterns {
+		if MatchGlob(pattern, rel, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchGlob reports whether the slash separated relative path matches the
+// pattern. A pattern without slash matches the name of the file or directory
+// in any depth, other patterns match the whole path. "**" matches any number
+// of directories and a trailing slash only matches directories.
+func MatchGlob(pattern, rel string, dir bool) bool {
+	if strings.HasSuffix(pattern, "/") {
+		if !dir {
+			return false
+		}
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(rel))
+		return matched
+	}
+	return matchSegments(strings.Split(strings.TrimPrefix(pattern, "/"), "/"), strings.Split(rel, "/"))
+}
+
+func matchSegments(patterns, segments []string) bool {
+	if len(patterns) == 0 {
+		return len(segments) == 0
+	}
+	if patterns[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(patterns[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(patterns[0], segments[0]); !matched {
+		return false
+	}
+	return matchSegments(patterns[1:], segments[1:])
+}
@@ -0,0 +1,108 @@
+package check_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/seibert-media/k8s-manifest-check/check"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const validPod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: hello-world
+spec:
+  containers:
+  - name: hello
+    image: "ubuntu:14.04"
+    resources:
+      limits:
+        cpu: 100m
+        memory: 50Mi
+      requests:
+        cpu: 10m
+        memory: 10Mi
+`
+
+var _ = Describe("Files", func() {
+	var dir string
+	var checker *check.Checker
+	write := func(name string, content string) {
+		file := filepath.Join(dir, name)
+		Expect(os.MkdirAll(filepath.Dir(file), 0755)).To(BeNil())
+		Expect(ioutil.WriteFile(file, []byte(content), 0644)).To(BeNil())
+	}
+	files := func() []string {
+		files, err := checker.Files(dir)
+		Expect(err).To(BeNil())
+		var result []string
+		for _, file := range files {
+			rel, err := filepath.Rel(dir, file)
+			Expect(err).To(BeNil())
+			result = append(result, filepath.ToSlash(rel))
+		}
+		return result
+	}
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "manifests")
+		Expect(err).To(BeNil())
+		checker = check.New()
+		write("pod.yaml", validPod)
+		write("app/deploy.yml", validPod)
+		write("app/config.json", `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "config"}}`)
+		write("app/README.md", "# app")
+		write("charts/app/templates/deploy.yaml", "{{ .Values }}")
+		write(".github/workflows/ci.yaml", "on: push")
+	})
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+	It("return manifests recursively", func() {
+		Expect(files()).To(Equal([]string{"app/config.json", "app/deploy.yml", "charts/app/templates/deploy.yaml", "pod.yaml"}))
+	})
+	It("skip excluded files and directories", func() {
+		checker.Exclude = []string{"charts/", "*.json"}
+		Expect(files()).To(Equal([]string{"app/deploy.yml", "pod.yaml"}))
+	})
+	It("return only included files", func() {
+		checker.Include = []string{"app/**/*.yml"}
+		Expect(files()).To(Equal([]string{"app/deploy.yml"}))
+	})
+	It("skip paths of ignore files", func() {
+		write(check.IgnoreFile, "# helm templates\ncharts/**/templates/\n")
+		write("app/"+check.IgnoreFile, "config.json\n")
+		Expect(files()).To(Equal([]string{"app/deploy.yml", "pod.yaml"}))
+	})
+	It("check all manifests of directory", func() {
+		write(check.IgnoreFile, "charts/\n")
+		report := &check.Report{}
+		checker.Path(report, dir)
+		Expect(report.Files).To(HaveLen(3))
+		Expect(report.Valid()).To(BeTrue())
+	})
+})
+
+var _ = Describe("MatchGlob", func() {
+	It("match name in any directory", func() {
+		Expect(check.MatchGlob("*.yaml", "a/b/c.yaml", false)).To(BeTrue())
+		Expect(check.MatchGlob("*.yaml", "a/b/c.json", false)).To(BeFalse())
+	})
+	It("match whole path", func() {
+		Expect(check.MatchGlob("a/*.yaml", "a/c.yaml", false)).To(BeTrue())
+		Expect(check.MatchGlob("a/*.yaml", "a/b/c.yaml", false)).To(BeFalse())
+		Expect(check.MatchGlob("/a/*.yaml", "a/c.yaml", false)).To(BeTrue())
+	})
+	It("match any number of directories", func() {
+		Expect(check.MatchGlob("a/**/c.yaml", "a/c.yaml", false)).To(BeTrue())
+		Expect(check.MatchGlob("a/**/c.yaml", "a/b/b/c.yaml", false)).To(BeTrue())
+		Expect(check.MatchGlob("**/c.yaml", "x/c.yaml", false)).To(BeTrue())
+	})
+	It("match directories only with trailing slash", func() {
+		Expect(check.MatchGlob("charts/", "charts", true)).To(BeTrue())
+		Expect(check.MatchGlob("charts/", "charts", false)).To(BeFalse())
+	})
+})
@@ -0,0 +1,89 @@
+package check
+
+import (
+	"encoding/json"
+
+	appsv1 "k8s.io/api/apps/v1"
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	appsv1beta2 "k8s.io/api/apps/v1beta2"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	batchv2alpha1 "k8s.io/api/batch/v2alpha1"
+	corev1 "k8s.io/api/core/v1"
+	extv1beta1 "k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8s_runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// PodTemplate returns the pod template of the given workload object.
+// For a Pod the template is built from the pod itself.
+// The boolean is false if the object does not create pods.
+func PodTemplate(obj k8s_runtime.Object) (*corev1.PodTemplateSpec, bool) {
+	switch o := obj.(type) {
+	case *corev1.Pod:
+		return &corev1.PodTemplateSpec{ObjectMeta: o.ObjectMeta, Spec: o.Spec}, true
+	case *corev1.PodTemplate:
+		return &o.Template, true
+	case *corev1.ReplicationController:
+		return o.Spec.Template, o.Spec.Template != nil
+	case *appsv1.Deployment:
+		return &o.Spec.Template, true
+	case *appsv1.StatefulSet:
+		return &o.Spec.Template, true
+	case *appsv1.DaemonSet:
+		return &o.Spec.Template, true
+	case *appsv1.ReplicaSet:
+		return &o.Spec.Template, true
+	case *appsv1beta1.Deployment:
+		return &o.Spec.Template, true
+	case *appsv1beta1.StatefulSet:
+		return &o.Spec.Template, true
+	case *appsv1beta2.Deployment:
+		return &o.Spec.Template, true
+	case *appsv1beta2.StatefulSet:
+		return &o.Spec.Template, true
+	case *appsv1beta2.DaemonSet:
+		return &o.Spec.Template, true
+	case *appsv1beta2.ReplicaSet:
+		return &o.Spec.Template, true
+	case *extv1beta1.Deployment:
+		return &o.Spec.Template, true
+	case *extv1beta1.DaemonSet:
+		return &o.Spec.Template, true
+	case *extv1beta1.ReplicaSet:
+		return &o.Spec.Template, true
+	case *batchv1.Job:
+		return &o.Spec.Template, true
+	case *batchv1beta1.CronJob:
+		return &o.Spec.JobTemplate.Spec.Template, true
+	case *batchv2alpha1.CronJob:
+		return &o.Spec.JobTemplate.Spec.Template, true
+	case *batchv1beta1.JobTemplate:
+		return &o.Template.Spec.Template, true
+	case *batchv2alpha1.JobTemplate:
+		return &o.Template.Spec.Template, true
+	case *unstructured.Unstructured:
+		if o.GetAPIVersion() == "batch/v1" && o.GetKind() == "CronJob" {
+			return unstructuredPodTemplate(o, "spec", "jobTemplate", "spec", "template")
+		}
+	}
+	return nil, false
+}
+
+// unstructuredPodTemplate decodes the pod template at the given fields of
+// an object missing in the vendored API versions.
+func unstructuredPodTemplate(obj *unstructured.Unstructured, fields ...string) (*corev1.PodTemplateSpec, bool) {
+	value, found, err := unstructured.NestedFieldNoCopy(obj.Object, fields...)
+	if !found || err != nil {
+		return nil, false
+	}
+	content, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+	template := &corev1.PodTemplateSpec{}
+	if err := json.Unmarshal(content, template); err != nil {
+		return nil, false
+	}
+	return template, true
+}
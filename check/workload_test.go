@@ -0,0 +1,69 @@
+package check_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/seibert-media/k8s-manifest-check/check"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PodTemplate", func() {
+	workloads := []struct {
+		apiVersion string
+		kind       string
+		body       string
+		// containerLine is the line and column of the container
+		containerLine string
+	}{
+		{"v1", "PodTemplate", podTemplateWithoutResources(0), "8:5"},
+		{"v1", "ReplicationController", "spec:\n" + podTemplateWithoutResources(2), "9:7"},
+		{"apps/v1", "Deployment", "spec:\n" + podTemplateWithoutResources(2), "9:7"},
+		{"apps/v1", "StatefulSet", "spec:\n" + podTemplateWithoutResources(2), "9:7"},
+		{"apps/v1", "DaemonSet", "spec:\n" + podTemplateWithoutResources(2), "9:7"},
+		{"apps/v1", "ReplicaSet", "spec:\n" + podTemplateWithoutResources(2), "9:7"},
+		{"apps/v1beta1", "Deployment", "spec:\n" + podTemplateWithoutResources(2), "9:7"},
+		{"apps/v1beta1", "StatefulSet", "spec:\n" + podTemplateWithoutResources(2), "9:7"},
+		{"apps/v1beta2", "Deployment", "spec:\n" + podTemplateWithoutResources(2), "9:7"},
+		{"apps/v1beta2", "StatefulSet", "spec:\n" + podTemplateWithoutResources(2), "9:7"},
+		{"apps/v1beta2", "DaemonSet", "spec:\n" + podTemplateWithoutResources(2), "9:7"},
+		{"apps/v1beta2", "ReplicaSet", "spec:\n" + podTemplateWithoutResources(2), "9:7"},
+		{"extensions/v1beta1", "Deployment", "spec:\n" + podTemplateWithoutResources(2), "9:7"},
+		{"extensions/v1beta1", "DaemonSet", "spec:\n" + podTemplateWithoutResources(2), "9:7"},
+		{"extensions/v1beta1", "ReplicaSet", "spec:\n" + podTemplateWithoutResources(2), "9:7"},
+		{"batch/v1", "Job", "spec:\n" + podTemplateWithoutResources(2), "9:7"},
+		{"batch/v1beta1", "CronJob", "spec:\n  jobTemplate:\n    spec:\n" + podTemplateWithoutResources(6), "11:11"},
+		{"batch/v2alpha1", "CronJob", "spec:\n  jobTemplate:\n    spec:\n" + podTemplateWithoutResources(6), "11:11"},
+		{"batch/v1beta1", "JobTemplate", "template:\n  spec:\n" + podTemplateWithoutResources(4), "10:9"},
+		{"batch/v2alpha1", "JobTemplate", "template:\n  spec:\n" + podTemplateWithoutResources(4), "10:9"},
+	}
+	for _, workload := range workloads {
+		workload := workload
+		It(fmt.Sprintf("check containers of %s %s", workload.apiVersion, workload.kind), func() {
+			content := fmt.Sprintf("apiVersion: %s\nkind: %s\nmetadata:\n  name: hello-world\n%s", workload.apiVersion, workload.kind, workload.body)
+			report := &check.Report{}
+			check.Content(report, "workload.yaml", []byte(content))
+			Expect(report.Findings).NotTo(BeEmpty())
+			Expect(report.Findings[0].String()).To(Equal(fmt.Sprintf("cpu request is zero in workload.yaml:%s (document 1, %s hello-world, container hello) [cpu-request-nonzero]", workload.containerLine, workload.kind)))
+		})
+	}
+	It("ignore replication controller without template", func() {
+		report := &check.Report{}
+		check.Content(report, "workload.yaml", []byte("apiVersion: v1\nkind: ReplicationController\nmetadata:\n  name: hello-world\n"))
+		Expect(report.Valid()).To(BeTrue())
+	})
+})
+
+// podTemplateWithoutResources returns a pod template indented by the given number of spaces.
+func podTemplateWithoutResources(indentation int) string {
+	lines := []string{
+		"template:",
+		"  spec:",
+		"    containers:",
+		"    - name: hello",
+		"      image: \"ubuntu:14.04\"",
+	}
+	prefix := strings.Repeat(" ", indentation)
+	return prefix + strings.Join(lines, "\n"+prefix) + "\n"
+}
@@ -5,29 +5,224 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/seibert-media/k8s-manifest-check/check"
+	"github.com/seibert-media/k8s-manifest-check/output"
 	"github.com/golang/glog"
 )
 
+var (
+	listRulesPtr     = flag.Bool("list-rules", false, "list all rules and exit")
+	enablePtr        = flag.String("enable", "", "comma separated list of rule IDs to enable")
+	disablePtr       = flag.String("disable", "", "comma separated list of rule IDs to disable")
+	includePtr       = flag.String("include", "", "comma separated list of glob patterns of files to check in directories")
+	excludePtr       = flag.String("exclude", "", "comma separated list of glob patterns of files and directories to skip in directories")
+	stdinNamePtr     = flag.String("stdin-name", "stdin", "source name of manifests read from stdin by the argument -")
+	configPtr        = flag.String("config", "", fmt.Sprintf("config file, defaults to %s in the working directory or its parents", check.ConfigFile))
+	outputPtr        = flag.String("output", "text", fmt.Sprintf("output format (%s)", strings.Join(output.Formats(), ", ")))
+	baselinePtr      = flag.String("baseline", "", "baseline file of known findings, which do not fail the check")
+	writeBaselinePtr = flag.String("write-baseline", "", "write all findings to the given baseline file and exit")
+	jobsPtr          = flag.Int("j", runtime.NumCPU(), "number of files checked concurrently")
+	targetVersionPtr = flag.String("target-kubernetes-version", "", "version of the target cluster like 1.22 to check for removed API versions")
+	strictPtr        = flag.Bool("strict", false, fmt.Sprintf("report unknown and misspelled fields, same as -enable=%s", check.UnknownFieldRule))
+	schemaPtr        = flag.String("schema", "", "comma separated list of OpenAPI v2 or v3 documents or directories of them to validate objects with")
+	crdPtr           = flag.String("crd", "", "comma separated list of CustomResourceDefinition manifests or directories of them to validate custom resources with")
+	failOnPtr        = flag.String("fail-on", string(check.SeverityInfo), "lowest severity of findings failing the check (error, warning, info, none)")
+)
+
+const (
+	// exitErrors is the exit code if findings with severity error fail the
+	// check. It is also used if the check can not be run.
+	exitErrors = 1
+	// exitWarnings is the exit code if only findings with lower severity fail the check.
+	exitWarnings = 2
+	// failOnNone never fails the check because of findings.
+	failOnNone = "none"
+)
+
 func main() {
 	defer glog.Flush()
 	glog.CopyStandardLogTo("info")
 	flag.Parse()
 	runtime.GOMAXPROCS(runtime.NumCPU())
 
+	if !output.Supported(*outputPtr) {
+		fmt.Printf("output format %s not supported\n", *outputPtr)
+		os.Exit(1)
+	}
+	if *failOnPtr != failOnNone && !check.Severity(*failOnPtr).Valid() {
+		fmt.Printf("fail-on severity %s not supported\n", *failOnPtr)
+		os.Exit(1)
+	}
+	checker := check.New()
+	checker.Include = splitList(*includePtr)
+	checker.Exclude = splitList(*excludePtr)
+	checker.Jobs = *jobsPtr
+	if err := configure(checker); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	if *listRulesPtr {
+		listRules(checker.Registry)
+		return
+	}
+
 	args := flag.Args()
 	glog.V(4).Infof("found %d args to validate", len(args))
 	if len(args) == 0 {
 		fmt.Println("missing arg")
 		os.Exit(1)
 	}
+	report := &check.Report{}
+	var paths []string
 	for _, arg := range args {
-		glog.V(4).Infof("handle manifest %s", arg)
-		if err := check.Path(arg); err != nil {
+		if arg == "-" {
+			glog.V(4).Infof("handle manifests of stdin")
+			checker.Reader(report, os.Stdin, *stdinNamePtr)
+			continue
+		}
+		paths = append(paths, arg)
+	}
+	glog.V(4).Infof("handle manifests %v", paths)
+	checker.Paths(report, paths...)
+	checker.References(report)
+	if *writeBaselinePtr != "" {
+		if err := check.NewBaseline(report.Findings).Save(*writeBaselinePtr); err != nil {
 			fmt.Println(err.Error())
 			os.Exit(1)
 		}
+		fmt.Printf("wrote %d findings to baseline %s\n", len(report.Findings), *writeBaselinePtr)
+		return
+	}
+	if *baselinePtr != "" {
+		baseline, err := check.LoadBaseline(*baselinePtr)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		baseline.Apply(report)
+		glog.V(1).Infof("ignore %d findings of baseline", len(report.Baselined))
+	}
+	if err := output.Write(os.Stdout, *outputPtr, report, checker.Registry); err != nil {
+		fmt.Printf("write output failed: %v\n", err)
+		os.Exit(1)
+	}
+	if !report.Valid() {
+		glog.V(1).Infof("found %d problems", len(report.Findings))
+		os.Exit(exitCode(report, *failOnPtr))
 	}
 	glog.V(1).Infof("all manifest are valid")
 }
+
+// exitCode returns the exit code for the findings of the report, which fail
+// the check if their severity is at least failOn.
+func exitCode(report *check.Report, failOn string) int {
+	max := report.Max()
+	if failOn == failOnNone || max == "" || !max.AtLeast(check.Severity(failOn)) {
+		return 0
+	}
+	if max == check.SeverityError {
+		return exitErrors
+	}
+	return exitWarnings
+}
+
+// configure loads the config file and enables and disables the rules given by
+// flags, which take precedence over the config file.
+func configure(checker *check.Checker) error {
+	file := *configPtr
+	if file == "" {
+		var ok bool
+		if file, ok = check.FindConfig("."); !ok {
+			glog.V(4).Infof("no config file found")
+		}
+	}
+	if file != "" {
+		glog.V(2).Infof("load config %s", file)
+		config, err := check.LoadConfig(file)
+		if err != nil {
+			return err
+		}
+		if err := config.Validate(checker.Registry); err != nil {
+			return fmt.Errorf("%v in %s", err, file)
+		}
+		if err := config.Apply(checker.Registry); err != nil {
+			return err
+		}
+		checker.Config = config
+	}
+	schemas := splitList(*schemaPtr)
+	if checker.Config != nil {
+		schemas = append(checker.Config.Schemas, schemas...)
+	}
+	if len(schemas) > 0 {
+		var err error
+		if checker.Schemas, err = check.LoadSchemas(schemas...); err != nil {
+			return err
+		}
+		glog.V(2).Infof("loaded schemas of %d kinds", checker.Schemas.Len())
+	}
+	crds := splitList(*crdPtr)
+	if checker.Config != nil {
+		crds = append(checker.Config.CRDs, crds...)
+	}
+	if len(crds) > 0 {
+		if checker.Schemas == nil {
+			checker.Schemas = check.NewSchemas()
+		}
+		if err := checker.Schemas.LoadCRDs(crds...); err != nil {
+			return err
+		}
+	}
+	if *targetVersionPtr != "" {
+		if checker.Config == nil {
+			checker.Config = &check.Config{}
+		}
+		if err := checker.Config.SetKubernetesVersion(*targetVersionPtr); err != nil {
+			return err
+		}
+	}
+	return configureRules(checker.Registry)
+}
+
+// configureRules enables and disables the rules given by flags, which take
+// precedence over the config file.
+func configureRules(registry *check.Registry) error {
+	if *strictPtr {
+		if err := registry.Force(check.UnknownFieldRule, true); err != nil {
+			return err
+		}
+	}
+	for _, id := range splitList(*enablePtr) {
+		if err := registry.Force(id, true); err != nil {
+			return err
+		}
+	}
+	for _, id := range splitList(*disablePtr) {
+		if err := registry.Force(id, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func listRules(registry *check.Registry) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tSEVERITY\tENABLED\tDESCRIPTION")
+	for _, rule := range registry.Rules() {
+		fmt.Fprintf(writer, "%s\t%s\t%t\t%s\n", rule.ID(), rule.Severity(), registry.Enabled(rule.ID()), rule.Description())
+	}
+	writer.Flush()
+}
+
+func splitList(list string) []string {
+	var result []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
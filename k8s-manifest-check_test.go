@@ -1,10 +1,12 @@
 package main
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path"
+	"strings"
 	"testing"
 	"time"
 
@@ -46,6 +48,20 @@ var _ = Describe("Check", func() {
 		Expect(serverSession.Buffer()).To(gbytes.Say("missing arg"))
 		Expect(serverSession.ExitCode()).To(Equal(1))
 	})
+	It("list rules", func() {
+		serverSession, err = gexec.Start(exec.Command(pathToServerBinary, "-list-rules"), GinkgoWriter, GinkgoWriter)
+		Expect(err).To(BeNil())
+		serverSession.Wait(100 * time.Millisecond)
+		Expect(serverSession.ExitCode()).To(Equal(0))
+		Expect(serverSession.Buffer()).To(gbytes.Say("cpu-request-nonzero"))
+	})
+	It("print error for unknown rule", func() {
+		serverSession, err = gexec.Start(exec.Command(pathToServerBinary, "-disable=unknown", "manifest.yaml"), GinkgoWriter, GinkgoWriter)
+		Expect(err).To(BeNil())
+		serverSession.Wait(100 * time.Millisecond)
+		Expect(serverSession.ExitCode()).To(Equal(1))
+		Expect(serverSession.Buffer()).To(gbytes.Say("rule unknown not found"))
+	})
 	Context("with manifests", func() {
 		var manifestpath string
 		AfterEach(func() {
@@ -151,6 +167,489 @@ spec:
 				Expect(serverSession.Buffer()).To(gbytes.Say("cpu request is zero in %s", manifestpath))
 			})
 		})
+		Context("disabled rules", func() {
+			BeforeEach(func() {
+				manifestpath = writeManifest(`apiVersion: v1
+kind: Pod
+metadata:
+  name: hello-world
+spec:
+  containers:
+  - name: hello
+    image: "ubuntu:14.04"
+    resources:
+      requests:
+        cpu: 100m
+        memory: 100Mi
+`)
+			})
+			It("print nothing", func() {
+				serverSession, err = gexec.Start(exec.Command(pathToServerBinary, "-disable=cpu-limit-nonzero,memory-limit-nonzero", manifestpath), GinkgoWriter, GinkgoWriter)
+				Expect(err).To(BeNil())
+				serverSession.Wait(100 * time.Millisecond)
+				Expect(serverSession.ExitCode()).To(Equal(0))
+			})
+		})
+		Context("json output", func() {
+			BeforeEach(func() {
+				manifestpath = writeManifest(`apiVersion: v1
+kind: Pod
+metadata:
+  name: hello-world
+spec:
+  containers:
+  - name: hello
+    image: "ubuntu:14.04"
+`)
+			})
+			It("print findings as json", func() {
+				serverSession, err = gexec.Start(exec.Command(pathToServerBinary, "-output=json", manifestpath), GinkgoWriter, GinkgoWriter)
+				Expect(err).To(BeNil())
+				serverSession.Wait(100 * time.Millisecond)
+				Expect(serverSession.ExitCode()).To(Equal(1))
+				Expect(serverSession.Buffer()).To(gbytes.Say(`"rule": "cpu-request-nonzero"`))
+				Expect(serverSession.Buffer()).To(gbytes.Say(`"findings": 4`))
+			})
+		})
+		Context("config file", func() {
+			var configpath string
+			BeforeEach(func() {
+				manifestpath = writeManifest(`apiVersion: v1
+kind: Pod
+metadata:
+  name: hello-world
+spec:
+  containers:
+  - name: hello
+    image: "ubuntu:14.04"
+    resources:
+      requests:
+        cpu: 100m
+        memory: 100Mi
+`)
+				configpath = writeManifest(`rules:
+  cpu-limit-nonzero:
+    enabled: false
+  memory-limit-nonzero:
+    severity: warning
+`)
+			})
+			AfterEach(func() {
+				os.Remove(configpath)
+			})
+			It("print findings with configured rules and severities", func() {
+				serverSession, err = gexec.Start(exec.Command(pathToServerBinary, "-config="+configpath, manifestpath), GinkgoWriter, GinkgoWriter)
+				Expect(err).To(BeNil())
+				serverSession.Wait(100 * time.Millisecond)
+				Expect(serverSession.ExitCode()).To(Equal(2))
+				Expect(serverSession.Buffer()).To(gbytes.Say(`memory limit is zero in .* \[memory-limit-nonzero\]`))
+				Expect(serverSession.Buffer()).NotTo(gbytes.Say("cpu limit is zero"))
+			})
+		})
+		Context("baseline", func() {
+			var baselinepath string
+			BeforeEach(func() {
+				manifestpath = writeManifest(`apiVersion: v1
+kind: Pod
+metadata:
+  name: hello-world
+spec:
+  containers:
+  - name: hello
+    image: "ubuntu:14.04"
+`)
+				baselinepath = manifestpath + ".baseline.json"
+			})
+			AfterEach(func() {
+				os.Remove(baselinepath)
+			})
+			It("fail only on findings not in the baseline", func() {
+				serverSession, err = gexec.Start(exec.Command(pathToServerBinary, "-write-baseline="+baselinepath, manifestpath), GinkgoWriter, GinkgoWriter)
+				Expect(err).To(BeNil())
+				serverSession.Wait(100 * time.Millisecond)
+				Expect(serverSession.ExitCode()).To(Equal(0))
+				Expect(serverSession.Buffer()).To(gbytes.Say("wrote 4 findings to baseline %s", baselinepath))
+
+				Expect(ioutil.WriteFile(manifestpath, []byte(`# comment moving all lines
+apiVersion: v1
+kind: Pod
+metadata:
+  name: hello-world
+spec:
+  containers:
+  - name: hello
+    image: "ubuntu:14.04"
+  - name: sidecar
+    image: "ubuntu:14.04"
+    resources:
+      limits:
+        cpu: 100m
+        memory: 100Mi
+      requests:
+        cpu: 100m
+        memory: 200Mi
+`), 0644)).To(BeNil())
+				serverSession, err = gexec.Start(exec.Command(pathToServerBinary, "-baseline="+baselinepath, manifestpath), GinkgoWriter, GinkgoWriter)
+				Expect(err).To(BeNil())
+				serverSession.Wait(100 * time.Millisecond)
+				Expect(serverSession.ExitCode()).To(Equal(1))
+				Expect(serverSession.Buffer()).To(gbytes.Say("memory request must be less than or equal to memory limit in %s", manifestpath))
+				Expect(serverSession.Buffer()).NotTo(gbytes.Say("container hello"))
+			})
+		})
+		Context("warnings only", func() {
+			BeforeEach(func() {
+				manifestpath = writeManifest(`apiVersion: v1
+kind: Pod
+metadata:
+  name: hello-world
+spec:
+  containers:
+  - name: hello
+    image: "ubuntu:14.04"
+    resources:
+      requests:
+        cpu: 100m
+        memory: 100Mi
+`)
+			})
+			It("exit with warnings code", func() {
+				serverSession, err = gexec.Start(exec.Command(pathToServerBinary, manifestpath), GinkgoWriter, GinkgoWriter)
+				Expect(err).To(BeNil())
+				serverSession.Wait(100 * time.Millisecond)
+				Expect(serverSession.ExitCode()).To(Equal(2))
+				Expect(serverSession.Buffer()).To(gbytes.Say("cpu limit is zero in %s", manifestpath))
+			})
+			It("succeed if failing on errors only", func() {
+				serverSession, err = gexec.Start(exec.Command(pathToServerBinary, "-fail-on=error", manifestpath), GinkgoWriter, GinkgoWriter)
+				Expect(err).To(BeNil())
+				serverSession.Wait(100 * time.Millisecond)
+				Expect(serverSession.ExitCode()).To(Equal(0))
+				Expect(serverSession.Buffer()).To(gbytes.Say("cpu limit is zero in %s", manifestpath))
+			})
+			It("print error for unknown severity", func() {
+				serverSession, err = gexec.Start(exec.Command(pathToServerBinary, "-fail-on=fatal", manifestpath), GinkgoWriter, GinkgoWriter)
+				Expect(err).To(BeNil())
+				serverSession.Wait(100 * time.Millisecond)
+				Expect(serverSession.ExitCode()).To(Equal(1))
+				Expect(serverSession.Buffer()).To(gbytes.Say("fail-on severity fatal not supported"))
+			})
+		})
+		Context("removed api version", func() {
+			BeforeEach(func() {
+				manifestpath = writeManifest(`apiVersion: apps/v1beta2
+kind: DaemonSet
+metadata:
+  name: hello-world
+spec:
+  template:
+    spec:
+      containers:
+      - name: hello
+        image: "ubuntu:14.04"
+        resources:
+          limits:
+            cpu: 100m
+            memory: 100Mi
+          requests:
+            cpu: 100m
+            memory: 100Mi
+`)
+			})
+			It("print error for target version", func() {
+				serverSession, err = gexec.Start(exec.Command(pathToServerBinary, "-target-kubernetes-version=1.16", manifestpath), GinkgoWriter, GinkgoWriter)
+				Expect(err).To(BeNil())
+				serverSession.Wait(100 * time.Millisecond)
+				Expect(serverSession.ExitCode()).To(Equal(1))
+				Expect(serverSession.Buffer()).To(gbytes.Say("apps/v1beta2 DaemonSet is removed in Kubernetes 1.16, use apps/v1 in %s", manifestpath))
+			})
+			It("print error for invalid target version", func() {
+				serverSession, err = gexec.Start(exec.Command(pathToServerBinary, "-target-kubernetes-version=latest", manifestpath), GinkgoWriter, GinkgoWriter)
+				Expect(err).To(BeNil())
+				serverSession.Wait(100 * time.Millisecond)
+				Expect(serverSession.ExitCode()).To(Equal(1))
+				Expect(serverSession.Buffer()).To(gbytes.Say("kubernetes version latest is invalid"))
+			})
+		})
+		Context("misspelled field", func() {
+			BeforeEach(func() {
+				manifestpath = writeManifest(`apiVersion: v1
+kind: Pod
+metadata:
+  name: hello-world
+spec:
+  containers:
+  - name: hello
+    image: "ubuntu:14.04"
+    resouces:
+      limits:
+        cpu: 100m
+        memory: 100Mi
+      requests:
+        cpu: 100m
+        memory: 100Mi
+`)
+			})
+			It("print unknown field in strict mode", func() {
+				serverSession, err = gexec.Start(exec.Command(pathToServerBinary, "-strict", manifestpath), GinkgoWriter, GinkgoWriter)
+				Expect(err).To(BeNil())
+				serverSession.Wait(100 * time.Millisecond)
+				Expect(serverSession.ExitCode()).To(Equal(1))
+				Expect(serverSession.Buffer()).To(gbytes.Say(`unknown field "resouces", did you mean "resources"\? in %s:9:5`, manifestpath))
+			})
+		})
+		Context("restricted pod", func() {
+			BeforeEach(func() {
+				manifestpath = writeManifest(`apiVersion: v1
+kind: Pod
+metadata:
+  name: hello-world
+spec:
+  securityContext:
+    runAsNonRoot: true
+    seccompProfile:
+      type: RuntimeDefault
+  containers:
+  - name: hello
+    image: "ubuntu:14.04"
+    securityContext:
+      allowPrivilegeEscalation: false
+      capabilities:
+        drop: ["ALL"]
+    ports:
+    - containerPort: 8080
+    startupProbe:
+      tcpSocket:
+        port: 8080
+    resources:
+      limits:
+        cpu: 100m
+        memory: 100Mi
+      requests:
+        cpu: 100m
+        memory: 100Mi
+`)
+			})
+			It("pass strict mode and the restricted level", func() {
+				serverSession, err = gexec.Start(exec.Command(pathToServerBinary, "-strict", "-enable=pod-security-restricted", manifestpath), GinkgoWriter, GinkgoWriter)
+				Expect(err).To(BeNil())
+				serverSession.Wait(100 * time.Millisecond)
+				Expect(serverSession.ExitCode()).To(Equal(0))
+			})
+		})
+		Context("custom resource", func() {
+			var crdpath string
+			BeforeEach(func() {
+				manifestpath = writeManifest(`apiVersion: example.com/v1
+kind: Backup
+metadata:
+  name: nightly
+spec:
+  schedule: hourly
+`)
+				crdpath = writeManifest(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: backups.example.com
+spec:
+  group: example.com
+  names:
+    kind: Backup
+  versions:
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              schedule:
+                type: string
+                enum: ["daily", "weekly"]
+`)
+			})
+			AfterEach(func() {
+				os.Remove(crdpath)
+			})
+			It("print parse error without crd", func() {
+				serverSession, err = gexec.Start(exec.Command(pathToServerBinary, manifestpath), GinkgoWriter, GinkgoWriter)
+				Expect(err).To(BeNil())
+				serverSession.Wait(100 * time.Millisecond)
+				Expect(serverSession.ExitCode()).To(Equal(1))
+				Expect(serverSession.Buffer()).To(gbytes.Say(`parse content failed in %s`, manifestpath))
+			})
+			It("validate against the crd", func() {
+				serverSession, err = gexec.Start(exec.Command(pathToServerBinary, "-crd", crdpath, manifestpath), GinkgoWriter, GinkgoWriter)
+				Expect(err).To(BeNil())
+				serverSession.Wait(100 * time.Millisecond)
+				Expect(serverSession.ExitCode()).To(Equal(1))
+				Expect(serverSession.Buffer()).To(gbytes.Say(`spec.schedule must be one of "daily", "weekly" in %s:6:3`, manifestpath))
+			})
+			It("check the crd together with its custom resources", func() {
+				serverSession, err = gexec.Start(exec.Command(pathToServerBinary, "-crd", crdpath, crdpath, manifestpath), GinkgoWriter, GinkgoWriter)
+				Expect(err).To(BeNil())
+				serverSession.Wait(100 * time.Millisecond)
+				Expect(serverSession.ExitCode()).To(Equal(1))
+				Expect(string(serverSession.Buffer().Contents())).NotTo(ContainSubstring("parse content failed"))
+				Expect(serverSession.Buffer()).To(gbytes.Say(`spec.schedule must be one of "daily", "weekly" in %s:6:3`, manifestpath))
+			})
+		})
+		Context("multiple invalid manifests", func() {
+			var otherpath string
+			BeforeEach(func() {
+				manifestpath = writeManifest(`apiVersion: v1
+kind: Pod
+metadata:
+  name: hello-world
+spec:
+  containers:
+  - name: hello
+    image: "ubuntu:14.04"
+`)
+				otherpath = writeManifest(`apiVersion: v1
+kind: Pod
+metadata:
+  name: hello-world
+spec:
+  containers:
+  - name: hello
+    image: "ubuntu:14.04"
+    resources:
+      limits:
+        cpu: 100m
+        memory: 100Mi
+      requests:
+        cpu: 200m
+        memory: 100Mi
+`)
+			})
+			AfterEach(func() {
+				os.Remove(otherpath)
+			})
+			It("print all findings", func() {
+				serverSession, err = gexec.Start(exec.Command(pathToServerBinary, manifestpath, otherpath), GinkgoWriter, GinkgoWriter)
+				Expect(err).To(BeNil())
+				serverSession.Wait(100 * time.Millisecond)
+				Expect(serverSession.ExitCode()).To(Equal(1))
+				// findings are ordered by path
+				output := string(serverSession.Out.Contents())
+				Expect(output).To(ContainSubstring("cpu request is zero in %s", manifestpath))
+				Expect(output).To(ContainSubstring("memory request is zero in %s", manifestpath))
+				Expect(output).To(ContainSubstring("memory limit is zero in %s", manifestpath))
+				Expect(output).To(ContainSubstring("cpu limit is zero in %s", manifestpath))
+				Expect(output).To(ContainSubstring("cpu request must be less than or equal to cpu limit in %s", otherpath))
+				if manifestpath < otherpath {
+					Expect(strings.Index(output, manifestpath)).To(BeNumerically("<", strings.Index(output, otherpath)))
+				} else {
+					Expect(strings.Index(output, otherpath)).To(BeNumerically("<", strings.Index(output, manifestpath)))
+				}
+			})
+		})
+		Context("directory", func() {
+			BeforeEach(func() {
+				var err error
+				manifestpath, err = ioutil.TempDir("", "manifests")
+				Expect(err).To(BeNil())
+				Expect(ioutil.WriteFile(path.Join(manifestpath, "pod.yaml"), []byte(`apiVersion: v1
+kind: Pod
+metadata:
+  name: hello-world
+spec:
+  containers:
+  - name: hello
+    image: "ubuntu:14.04"
+`), 0644)).To(BeNil())
+				Expect(ioutil.WriteFile(path.Join(manifestpath, "skip.yaml"), []byte(`hello world`), 0644)).To(BeNil())
+			})
+			AfterEach(func() {
+				os.RemoveAll(manifestpath)
+			})
+			It("print findings of all manifests not excluded", func() {
+				serverSession, err = gexec.Start(exec.Command(pathToServerBinary, "-exclude=skip.yaml", manifestpath), GinkgoWriter, GinkgoWriter)
+				Expect(err).To(BeNil())
+				serverSession.Wait(100 * time.Millisecond)
+				Expect(serverSession.ExitCode()).To(Equal(1))
+				Expect(serverSession.Buffer()).To(gbytes.Say("cpu request is zero in %s", path.Join(manifestpath, "pod.yaml")))
+				Expect(serverSession.Buffer()).NotTo(gbytes.Say("skip.yaml"))
+			})
+		})
+		Context("references between manifests", func() {
+			BeforeEach(func() {
+				var err error
+				manifestpath, err = ioutil.TempDir("", "manifests")
+				Expect(err).To(BeNil())
+				Expect(ioutil.WriteFile(path.Join(manifestpath, "pod.yaml"), []byte(`apiVersion: v1
+kind: Pod
+metadata:
+  name: hello-world
+spec:
+  containers:
+  - name: hello
+    image: "ubuntu:14.04"
+    envFrom:
+    - configMapRef:
+        name: hello
+    - secretRef:
+        name: hello
+`), 0644)).To(BeNil())
+				Expect(ioutil.WriteFile(path.Join(manifestpath, "configmap.yaml"), []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: hello
+data:
+  greeting: hello
+`), 0644)).To(BeNil())
+				Expect(ioutil.WriteFile(path.Join(manifestpath, "worker.yaml"), []byte(`apiVersion: v1
+kind: Pod
+metadata:
+  name: worker
+spec:
+  containers:
+  - name: worker
+    image: "ubuntu:14.04"
+`), 0644)).To(BeNil())
+			})
+			AfterEach(func() {
+				os.RemoveAll(manifestpath)
+			})
+			It("print references missing in all manifests ordered by file", func() {
+				serverSession, err = gexec.Start(exec.Command(pathToServerBinary, "-enable=reference-configmap,reference-secret", manifestpath), GinkgoWriter, GinkgoWriter)
+				Expect(err).To(BeNil())
+				serverSession.Wait(100 * time.Millisecond)
+				Expect(serverSession.ExitCode()).To(Equal(1))
+				output := string(serverSession.Out.Contents())
+				missing := fmt.Sprintf("Secret hello not found in %s:13:9", path.Join(manifestpath, "pod.yaml"))
+				Expect(output).To(ContainSubstring(missing))
+				Expect(output).NotTo(ContainSubstring("reference-configmap"))
+				Expect(strings.Index(output, missing)).To(BeNumerically("<", strings.Index(output, path.Join(manifestpath, "worker.yaml"))))
+			})
+			It("check references only if enabled", func() {
+				serverSession, err = gexec.Start(exec.Command(pathToServerBinary, manifestpath), GinkgoWriter, GinkgoWriter)
+				Expect(err).To(BeNil())
+				serverSession.Wait(100 * time.Millisecond)
+				Expect(string(serverSession.Out.Contents())).NotTo(ContainSubstring("reference-secret"))
+			})
+		})
+		Context("stdin", func() {
+			It("print findings with source name", func() {
+				command := exec.Command(pathToServerBinary, "-stdin-name=kustomize", "-")
+				command.Stdin = strings.NewReader(`apiVersion: v1
+kind: Pod
+metadata:
+  name: hello-world
+spec:
+  containers:
+  - name: hello
+    image: "ubuntu:14.04"
+`)
+				serverSession, err = gexec.Start(command, GinkgoWriter, GinkgoWriter)
+				Expect(err).To(BeNil())
+				serverSession.Wait(100 * time.Millisecond)
+				Expect(serverSession.ExitCode()).To(Equal(1))
+				Expect(serverSession.Buffer()).To(gbytes.Say("cpu request is zero in kustomize:7:3"))
+			})
+		})
 		Context("not existing manifest", func() {
 			BeforeEach(func() {
 				manifestpath = path.Join(os.TempDir(), "not-existing-file")
@@ -160,7 +659,7 @@ spec:
 				Expect(err).To(BeNil())
 				serverSession.Wait(100 * time.Millisecond)
 				Expect(serverSession.ExitCode()).To(Equal(1))
-				Expect(serverSession.Buffer()).To(gbytes.Say("manifest %s not found", manifestpath))
+				Expect(serverSession.Buffer()).To(gbytes.Say("manifest not found in %s", manifestpath))
 			})
 		})
 	})
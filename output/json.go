@@ -0,0 +1,111 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/seibert-media/k8s-manifest-check/check"
+)
+
+// JSONVersion is the version of the JSON schema. It is increased on every
+// incompatible change, adding fields is considered compatible.
+const JSONVersion = 1
+
+type jsonReport struct {
+	Version    int           `json:"version"`
+	Findings   []jsonFinding `json:"findings"`
+	Suppressed []jsonFinding `json:"suppressed"`
+	Summary    jsonSummary   `json:"summary"`
+}
+
+type jsonFinding struct {
+	File      string         `json:"file"`
+	Document  int            `json:"document,omitempty"`
+	Line      int            `json:"line,omitempty"`
+	Column    int            `json:"column,omitempty"`
+	Object    *jsonObject    `json:"object,omitempty"`
+	Container *jsonContainer `json:"container,omitempty"`
+	Field     string         `json:"field,omitempty"`
+	Rule      string         `json:"rule"`
+	Severity  check.Severity `json:"severity"`
+	Message   string         `json:"message"`
+	// Justification is only set for suppressed findings.
+	Justification string `json:"justification,omitempty"`
+}
+
+type jsonObject struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+type jsonContainer struct {
+	Type check.ContainerType `json:"type"`
+	Name string              `json:"name"`
+}
+
+type jsonSummary struct {
+	Files    int `json:"files"`
+	Findings int `json:"findings"`
+	Errors   int `json:"errors"`
+	Warnings int `json:"warnings"`
+	Info     int `json:"info"`
+	// Suppressed is the number of suppressed findings, which are not counted by severity.
+	Suppressed int `json:"suppressed"`
+	// Baselined is the number of findings known by the baseline, which are not counted by severity.
+	Baselined int `json:"baselined"`
+}
+
+// JSON writes the report as a single JSON document.
+func JSON(writer io.Writer, report *check.Report, registry *check.Registry) error {
+	result := jsonReport{
+		Version:    JSONVersion,
+		Findings:   []jsonFinding{},
+		Suppressed: []jsonFinding{},
+		Summary: jsonSummary{
+			Files:      len(report.Files),
+			Findings:   len(report.Findings),
+			Errors:     report.Count(check.SeverityError),
+			Warnings:   report.Count(check.SeverityWarning),
+			Info:       report.Count(check.SeverityInfo),
+			Suppressed: len(report.Suppressed),
+			Baselined:  len(report.Baselined),
+		},
+	}
+	for _, finding := range report.Findings {
+		result.Findings = append(result.Findings, jsonFindingOf(finding))
+	}
+	for _, finding := range report.Suppressed {
+		result.Suppressed = append(result.Suppressed, jsonFindingOf(finding))
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+func jsonFindingOf(finding check.Finding) jsonFinding {
+	result := jsonFinding{
+		File:          finding.File,
+		Document:      finding.Document,
+		Line:          finding.Line,
+		Column:        finding.Column,
+		Field:         finding.Field,
+		Rule:          finding.Rule,
+		Severity:      finding.Severity,
+		Message:       finding.Message,
+		Justification: finding.Justification,
+	}
+	if finding.Kind != "" {
+		result.Object = &jsonObject{
+			APIVersion: finding.APIVersion,
+			Kind:       finding.Kind,
+			Namespace:  finding.Namespace,
+			Name:       finding.Name,
+		}
+	}
+	if finding.Container != "" {
+		result.Container = &jsonContainer{Type: finding.ContainerType, Name: finding.Container}
+	}
+	return result
+}
@@ -0,0 +1,71 @@
+package output_test
+
+import (
+	"bytes"
+
+	"github.com/seibert-media/k8s-manifest-check/check"
+	"github.com/seibert-media/k8s-manifest-check/output"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JSON", func() {
+	It("write findings and summary", func() {
+		buffer := &bytes.Buffer{}
+		Expect(output.JSON(buffer, newReport(), check.NewRegistry())).To(BeNil())
+		Expect(buffer.String()).To(MatchJSON(`{
+  "version": 1,
+  "findings": [
+    {
+      "file": "deploy.yaml",
+      "document": 2,
+      "line": 12,
+      "column": 9,
+      "object": {"apiVersion": "apps/v1", "kind": "Deployment", "namespace": "default", "name": "web"},
+      "container": {"type": "container", "name": "app"},
+      "field": "spec.template.spec.containers[0].resources.requests.cpu",
+      "rule": "cpu-request-nonzero",
+      "severity": "error",
+      "message": "cpu request is zero"
+    },
+    {
+      "file": "service.yaml",
+      "rule": "parse",
+      "severity": "error",
+      "message": "content is empty"
+    }
+  ],
+  "suppressed": [],
+  "summary": {"files": 2, "findings": 2, "errors": 2, "warnings": 0, "info": 0, "suppressed": 0, "baselined": 0}
+}`))
+	})
+	It("write empty list without findings", func() {
+		buffer := &bytes.Buffer{}
+		Expect(output.JSON(buffer, &check.Report{}, check.NewRegistry())).To(BeNil())
+		Expect(buffer.String()).To(MatchJSON(`{"version": 1, "findings": [], "suppressed": [], "summary": {"files": 0, "findings": 0, "errors": 0, "warnings": 0, "info": 0, "suppressed": 0, "baselined": 0}}`))
+	})
+	It("write suppressed findings with justification", func() {
+		buffer := &bytes.Buffer{}
+		Expect(output.JSON(buffer, newSuppressedReport(), check.NewRegistry())).To(BeNil())
+		Expect(buffer.String()).To(MatchJSON(`{
+  "version": 1,
+  "findings": [],
+  "suppressed": [
+    {
+      "file": "job.yaml",
+      "document": 1,
+      "line": 20,
+      "column": 9,
+      "object": {"apiVersion": "batch/v1", "kind": "Job", "namespace": "batch", "name": "nightly"},
+      "container": {"type": "container", "name": "worker"},
+      "field": "spec.template.spec.containers[0].resources.limits.cpu",
+      "rule": "cpu-limit-nonzero",
+      "severity": "error",
+      "message": "cpu limit is zero",
+      "justification": "nightly batch may use idle cpu"
+    }
+  ],
+  "summary": {"files": 1, "findings": 0, "errors": 0, "warnings": 0, "info": 0, "suppressed": 1, "baselined": 0}
+}`))
+	})
+})
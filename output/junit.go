@@ -0,0 +1,188 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/seibert-media/k8s-manifest-check/check"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr,omitempty"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr,omitempty"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// junitKey identifies the test case of a finding.
+type junitKey struct {
+	file     string
+	document int
+	rule     string
+}
+
+// JUnit writes the report as JUnit XML. Every file is a test suite and every
+// pair of checked object and rule active for it is a test case, which fails if the
+// rule reported findings for the object. Findings which do not belong to a
+// checked object, like parse errors, are failed test cases of their own.
+// Test cases with suppressed findings only are skipped.
+func JUnit(writer io.Writer, report *check.Report, registry *check.Registry) error {
+	findings := make(map[junitKey][]check.Finding)
+	suppressed := make(map[junitKey][]check.Finding)
+	var keys []junitKey
+	for _, finding := range report.Findings {
+		key := junitKey{file: finding.File, document: finding.Document, rule: finding.Rule}
+		if _, ok := findings[key]; !ok {
+			keys = append(keys, key)
+		}
+		findings[key] = append(findings[key], finding)
+	}
+	for _, finding := range report.Suppressed {
+		key := junitKey{file: finding.File, document: finding.Document, rule: finding.Rule}
+		suppressed[key] = append(suppressed[key], finding)
+	}
+
+	suites := make(map[string]*junitTestSuite)
+	var files []string
+	suite := func(file string) *junitTestSuite {
+		if _, ok := suites[file]; !ok {
+			suites[file] = &junitTestSuite{Name: file}
+			files = append(files, file)
+		}
+		return suites[file]
+	}
+	for _, file := range report.Files {
+		suite(file)
+	}
+	done := make(map[junitKey]bool)
+	for _, obj := range report.Objects {
+		for _, rule := range registry.Rules() {
+			if !registry.Active(rule, obj) {
+				continue
+			}
+			key := junitKey{file: obj.File, document: obj.Document, rule: rule.ID()}
+			done[key] = true
+			suite(obj.File).add(junitTestCase{
+				ClassName: obj.File,
+				Name:      fmt.Sprintf("%s %s: %s", obj.Kind, obj.QualifiedName(), rule.ID()),
+				Failure:   junitFailureOf(findings[key]),
+				Skipped:   junitSkippedOf(findings[key], suppressed[key]),
+			})
+		}
+	}
+	for _, key := range keys {
+		if done[key] {
+			continue
+		}
+		name := key.rule
+		if key.document > 0 {
+			name = fmt.Sprintf("document %d: %s", key.document, key.rule)
+		}
+		suite(key.file).add(junitTestCase{
+			ClassName: key.file,
+			Name:      name,
+			Failure:   junitFailureOf(findings[key]),
+		})
+	}
+
+	result := junitTestSuites{}
+	for _, file := range files {
+		result.Tests += suites[file].Tests
+		result.Failures += suites[file].Failures
+		result.Skipped += suites[file].Skipped
+		result.Suites = append(result.Suites, *suites[file])
+	}
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		return err
+	}
+	_, err := io.WriteString(writer, "\n")
+	return err
+}
+
+func (j *junitTestSuite) add(testCase junitTestCase) {
+	j.Tests++
+	if testCase.Failure != nil {
+		j.Failures++
+	}
+	if testCase.Skipped != nil {
+		j.Skipped++
+	}
+	j.TestCases = append(j.TestCases, testCase)
+}
+
+// junitFailureOf returns the failure of a test case or nil if there are no findings.
+func junitFailureOf(findings []check.Finding) *junitFailure {
+	if len(findings) == 0 {
+		return nil
+	}
+	var messages, lines []string
+	for _, finding := range findings {
+		messages = append(messages, finding.Message)
+		lines = append(lines, finding.String())
+	}
+	return &junitFailure{
+		Message: strings.Join(messages, "; "),
+		Type:    string(findings[0].Severity),
+		Text:    strings.Join(lines, "\n"),
+	}
+}
+
+// junitSkippedOf returns the skip of a test case with suppressed findings only or nil otherwise.
+func junitSkippedOf(findings, suppressed []check.Finding) *junitSkipped {
+	if len(findings) > 0 || len(suppressed) == 0 {
+		return nil
+	}
+	var justifications []string
+	for _, finding := range suppressed {
+		if finding.Justification != "" && !containsString(justifications, finding.Justification) {
+			justifications = append(justifications, finding.Justification)
+		}
+	}
+	message := "suppressed"
+	if len(justifications) > 0 {
+		message = fmt.Sprintf("suppressed: %s", strings.Join(justifications, "; "))
+	}
+	return &junitSkipped{Message: message}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package output_test
+
+import (
+	"bytes"
+
+	"github.com/seibert-media/k8s-manifest-check/check"
+	"github.com/seibert-media/k8s-manifest-check/output"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// kindRule is a test rule, which applies to objects of its kind only.
+type kindRule struct {
+	testRule
+	kind string
+}
+
+func (k *kindRule) Applies(obj *check.Object) bool {
+	return obj.Kind == k.kind
+}
+
+var _ = Describe("JUnit", func() {
+	It("write test suite per file and test case per object and rule", func() {
+		registry := check.NewRegistry()
+		registry.Register(&testRule{id: "cpu-request-nonzero"})
+		registry.Register(&testRule{id: "memory-request-nonzero"})
+		registry.Register(&testRule{id: "disabled"})
+		registry.Disable("disabled")
+		report := newReport()
+		report.Objects = []*check.Object{
+			{File: "deploy.yaml", Document: 2, APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "web"},
+		}
+		buffer := &bytes.Buffer{}
+		Expect(output.JUnit(buffer, report, registry)).To(BeNil())
+		Expect(buffer.String()).To(Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" failures="2">
+  <testsuite name="deploy.yaml" tests="2" failures="1">
+    <testcase classname="deploy.yaml" name="Deployment default/web: cpu-request-nonzero">
+      <failure message="cpu request is zero" type="error">cpu request is zero in deploy.yaml:12:9 (document 2, Deployment default/web, container app) [cpu-request-nonzero]</failure>
+    </testcase>
+    <testcase classname="deploy.yaml" name="Deployment default/web: memory-request-nonzero"></testcase>
+  </testsuite>
+  <testsuite name="service.yaml" tests="1" failures="1">
+    <testcase classname="service.yaml" name="parse">
+      <failure message="content is empty" type="error">content is empty in service.yaml [parse]</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`))
+	})
+	It("skip test cases with suppressed findings only", func() {
+		registry := check.NewRegistry()
+		registry.Register(&testRule{id: "cpu-limit-nonzero"})
+		buffer := &bytes.Buffer{}
+		Expect(output.JUnit(buffer, newSuppressedReport(), registry)).To(BeNil())
+		Expect(buffer.String()).To(Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="1" failures="0" skipped="1">
+  <testsuite name="job.yaml" tests="1" failures="0" skipped="1">
+    <testcase classname="job.yaml" name="Job batch/nightly: cpu-limit-nonzero">
+      <skipped message="suppressed: nightly batch may use idle cpu"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`))
+	})
+	It("write test cases for rules active for the object only", func() {
+		registry := check.NewRegistry()
+		registry.Register(&testRule{id: "always"})
+		registry.Register(&testRule{id: "disabled"})
+		registry.Register(&kindRule{testRule: testRule{id: "secret-only"}, kind: "Secret"})
+		registry.Disable("disabled")
+		config, err := check.ParseConfig([]byte(`overrides:
+- namespaces: [kube-system]
+  rules:
+    always:
+      enabled: false
+    disabled:
+      enabled: true
+`))
+		Expect(err).To(BeNil())
+		checker := &check.Checker{Registry: registry, Config: config}
+		report := &check.Report{}
+		checker.Content(report, "objects.yaml", []byte(`apiVersion: v1
+kind: Secret
+metadata:
+  name: app
+  namespace: default
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: coredns
+  namespace: kube-system
+`))
+		buffer := &bytes.Buffer{}
+		Expect(output.JUnit(buffer, report, registry)).To(BeNil())
+		Expect(buffer.String()).To(Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" failures="0">
+  <testsuite name="objects.yaml" tests="3" failures="0">
+    <testcase classname="objects.yaml" name="Secret default/app: always"></testcase>
+    <testcase classname="objects.yaml" name="Secret default/app: secret-only"></testcase>
+    <testcase classname="objects.yaml" name="ConfigMap kube-system/coredns: disabled"></testcase>
+  </testsuite>
+</testsuites>
+`))
+	})
+})
@@ -0,0 +1,65 @@
+// Package output writes reports of k8s-manifest-check in different formats.
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/seibert-media/k8s-manifest-check/check"
+)
+
+// Writer writes the report in a specific format. The registry describes the rules of the findings.
+type Writer func(writer io.Writer, report *check.Report, registry *check.Registry) error
+
+var writers = map[string]Writer{
+	"text":  Text,
+	"json":  JSON,
+	"sarif": SARIF,
+	"junit": JUnit,
+}
+
+// Formats returns the names of all supported formats.
+func Formats() []string {
+	var result []string
+	for format := range writers {
+		result = append(result, format)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Supported returns true if the format is supported.
+func Supported(format string) bool {
+	_, ok := writers[format]
+	return ok
+}
+
+// Write writes the report in the given format.
+func Write(writer io.Writer, format string, report *check.Report, registry *check.Registry) error {
+	w, ok := writers[format]
+	if !ok {
+		return fmt.Errorf("output format %s not supported", format)
+	}
+	return w(writer, report, registry)
+}
+
+// Text writes one line per finding followed by one line per suppressed
+// finding with its justification.
+func Text(writer io.Writer, report *check.Report, registry *check.Registry) error {
+	for _, finding := range report.Findings {
+		if _, err := fmt.Fprintln(writer, finding.String()); err != nil {
+			return err
+		}
+	}
+	for _, finding := range report.Suppressed {
+		line := fmt.Sprintf("suppressed %s", finding.String())
+		if finding.Justification != "" {
+			line = fmt.Sprintf("%s: %s", line, finding.Justification)
+		}
+		if _, err := fmt.Fprintln(writer, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package output_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/seibert-media/k8s-manifest-check/check"
+	"github.com/seibert-media/k8s-manifest-check/output"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestOutput(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Output Suite")
+}
+
+type testRule struct {
+	id string
+}
+
+func (t *testRule) ID() string {
+	return t.id
+}
+
+func (t *testRule) Description() string {
+	return "test rule"
+}
+
+func (t *testRule) Severity() check.Severity {
+	return check.SeverityError
+}
+
+func (t *testRule) Check(obj *check.Object) []check.Finding {
+	return nil
+}
+
+func newReport() *check.Report {
+	return &check.Report{
+		Files: []string{"deploy.yaml", "service.yaml"},
+		Findings: []check.Finding{
+			{
+				File:          "deploy.yaml",
+				Document:      2,
+				Line:          12,
+				Column:        9,
+				APIVersion:    "apps/v1",
+				Kind:          "Deployment",
+				Namespace:     "default",
+				Name:          "web",
+				ContainerType: check.RegularContainerType,
+				Container:     "app",
+				Field:         "spec.template.spec.containers[0].resources.requests.cpu",
+				Rule:          "cpu-request-nonzero",
+				Severity:      check.SeverityError,
+				Message:       "cpu request is zero",
+			},
+			{
+				File:     "service.yaml",
+				Rule:     check.ParseRule,
+				Severity: check.SeverityError,
+				Message:  "content is empty",
+			},
+		},
+	}
+}
+
+// newSuppressedReport returns a report with a single finding suppressed by annotation.
+func newSuppressedReport() *check.Report {
+	return &check.Report{
+		Files: []string{"job.yaml"},
+		Objects: []*check.Object{
+			{File: "job.yaml", Document: 1, APIVersion: "batch/v1", Kind: "Job", Namespace: "batch", Name: "nightly"},
+		},
+		Suppressed: []check.Finding{
+			{
+				File:          "job.yaml",
+				Document:      1,
+				Line:          20,
+				Column:        9,
+				APIVersion:    "batch/v1",
+				Kind:          "Job",
+				Namespace:     "batch",
+				Name:          "nightly",
+				ContainerType: check.RegularContainerType,
+				Container:     "worker",
+				Field:         "spec.template.spec.containers[0].resources.limits.cpu",
+				Rule:          "cpu-limit-nonzero",
+				Severity:      check.SeverityError,
+				Message:       "cpu limit is zero",
+				Justification: "nightly batch may use idle cpu",
+			},
+		},
+	}
+}
+
+var _ = Describe("Write", func() {
+	It("return error for unknown format", func() {
+		Expect(output.Supported("xml")).To(BeFalse())
+		Expect(output.Write(&bytes.Buffer{}, "xml", newReport(), check.NewRegistry())).NotTo(BeNil())
+	})
+	It("write text", func() {
+		buffer := &bytes.Buffer{}
+		Expect(output.Write(buffer, "text", newReport(), check.NewRegistry())).To(BeNil())
+		Expect(buffer.String()).To(Equal(`cpu request is zero in deploy.yaml:12:9 (document 2, Deployment default/web, container app) [cpu-request-nonzero]
+content is empty in service.yaml [parse]
+`))
+	})
+	It("write suppressed findings with justification", func() {
+		buffer := &bytes.Buffer{}
+		Expect(output.Write(buffer, "text", newSuppressedReport(), check.NewRegistry())).To(BeNil())
+		Expect(buffer.String()).To(Equal(`suppressed cpu limit is zero in job.yaml:20:9 (document 1, Job batch/nightly, container worker) [cpu-limit-nonzero]: nightly batch may use idle cpu
+`))
+	})
+})
@@ -0,0 +1,180 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/seibert-media/k8s-manifest-check/check"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string                `json:"name"`
+	InformationURI string                `json:"informationUri"`
+	Rules          []sarifRuleDescriptor `json:"rules"`
+}
+
+type sarifRuleDescriptor struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level   string `json:"level"`
+	Enabled bool   `json:"enabled"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	RuleIndex    int                `json:"ruleIndex"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// SARIF writes the report as SARIF 2.1.0 log with a single run. Every rule of
+// the registry is described in the tool, findings of unknown rules get a
+// descriptor without description. Suppressed findings are results with an
+// in source suppression.
+func SARIF(writer io.Writer, report *check.Report, registry *check.Registry) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "k8s-manifest-check",
+				InformationURI: "https://github.com/seibert-media/k8s-manifest-check",
+				Rules:          []sarifRuleDescriptor{},
+			},
+		},
+		Results: []sarifResult{},
+	}
+	ruleIndex := make(map[string]int)
+	addRule := func(descriptor sarifRuleDescriptor) {
+		ruleIndex[descriptor.ID] = len(run.Tool.Driver.Rules)
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, descriptor)
+	}
+	addRule(sarifRuleDescriptor{
+		ID:                   check.ReadRule,
+		ShortDescription:     sarifMessage{Text: "manifest can be read"},
+		DefaultConfiguration: sarifConfiguration{Level: sarifLevel(check.SeverityError), Enabled: true},
+	})
+	addRule(sarifRuleDescriptor{
+		ID:                   check.ParseRule,
+		ShortDescription:     sarifMessage{Text: "manifest can be parsed"},
+		DefaultConfiguration: sarifConfiguration{Level: sarifLevel(check.SeverityError), Enabled: true},
+	})
+	for _, rule := range registry.Rules() {
+		addRule(sarifRuleDescriptor{
+			ID:                   rule.ID(),
+			ShortDescription:     sarifMessage{Text: rule.Description()},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(rule.Severity()), Enabled: registry.Enabled(rule.ID())},
+		})
+	}
+	addResult := func(finding check.Finding, suppressions []sarifSuppression) {
+		index, ok := ruleIndex[finding.Rule]
+		if !ok {
+			addRule(sarifRuleDescriptor{
+				ID:                   finding.Rule,
+				ShortDescription:     sarifMessage{Text: finding.Rule},
+				DefaultConfiguration: sarifConfiguration{Level: sarifLevel(finding.Severity), Enabled: true},
+			})
+			index = ruleIndex[finding.Rule]
+		}
+		location := sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(finding.File)},
+		}
+		if finding.Line > 0 {
+			location.Region = &sarifRegion{StartLine: finding.Line, StartColumn: finding.Column}
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:       finding.Rule,
+			RuleIndex:    index,
+			Level:        sarifLevel(finding.Severity),
+			Message:      sarifMessage{Text: sarifMessageText(finding)},
+			Locations:    []sarifLocation{{PhysicalLocation: location}},
+			Suppressions: suppressions,
+		})
+	}
+	for _, finding := range report.Findings {
+		addResult(finding, nil)
+	}
+	for _, finding := range report.Suppressed {
+		addResult(finding, []sarifSuppression{{Kind: "inSource", Justification: finding.Justification}})
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{run},
+	})
+}
+
+// sarifLevel maps the severity to the SARIF result level.
+func sarifLevel(severity check.Severity) string {
+	switch severity {
+	case check.SeverityWarning:
+		return "warning"
+	case check.SeverityInfo:
+		return "note"
+	}
+	return "error"
+}
+
+// sarifMessageText returns the message of the finding followed by the object
+// and container it applies to.
+func sarifMessageText(finding check.Finding) string {
+	if context := finding.Context(); context != "" {
+		return fmt.Sprintf("%s (%s)", finding.Message, context)
+	}
+	return finding.Message
+}
@@ -0,0 +1,59 @@
+package output_test
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/seibert-media/k8s-manifest-check/check"
+	"github.com/seibert-media/k8s-manifest-check/output"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SARIF", func() {
+	var log map[string]interface{}
+	var run map[string]interface{}
+	BeforeEach(func() {
+		buffer := &bytes.Buffer{}
+		Expect(output.SARIF(buffer, newReport(), check.DefaultRegistry)).To(BeNil())
+		Expect(json.Unmarshal(buffer.Bytes(), &log)).To(BeNil())
+		run = log["runs"].([]interface{})[0].(map[string]interface{})
+	})
+	It("write version 2.1.0", func() {
+		Expect(log["version"]).To(Equal("2.1.0"))
+	})
+	It("describe all rules of the registry", func() {
+		rules := run["tool"].(map[string]interface{})["driver"].(map[string]interface{})["rules"].([]interface{})
+		var ids []string
+		for _, rule := range rules {
+			ids = append(ids, rule.(map[string]interface{})["id"].(string))
+		}
+		Expect(ids).To(ContainElement(check.ParseRule))
+		for _, rule := range check.DefaultRegistry.Rules() {
+			Expect(ids).To(ContainElement(rule.ID()))
+		}
+	})
+	It("write result for every finding", func() {
+		results := run["results"].([]interface{})
+		Expect(results).To(HaveLen(2))
+		result, err := json.Marshal(results[0])
+		Expect(err).To(BeNil())
+		Expect(result).To(MatchJSON(`{
+  "ruleId": "cpu-request-nonzero",
+  "ruleIndex": 2,
+  "level": "error",
+  "message": {"text": "cpu request is zero (document 2, Deployment default/web, container app)"},
+  "locations": [{"physicalLocation": {"artifactLocation": {"uri": "deploy.yaml"}, "region": {"startLine": 12, "startColumn": 9}}}]
+}`))
+	})
+	It("write suppressed findings as suppressed results", func() {
+		buffer := &bytes.Buffer{}
+		Expect(output.SARIF(buffer, newSuppressedReport(), check.DefaultRegistry)).To(BeNil())
+		Expect(json.Unmarshal(buffer.Bytes(), &log)).To(BeNil())
+		results := log["runs"].([]interface{})[0].(map[string]interface{})["results"].([]interface{})
+		Expect(results).To(HaveLen(1))
+		suppressions, err := json.Marshal(results[0].(map[string]interface{})["suppressions"])
+		Expect(err).To(BeNil())
+		Expect(suppressions).To(MatchJSON(`[{"kind": "inSource", "justification": "nightly batch may use idle cpu"}]`))
+	})
+})